@@ -0,0 +1,215 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import "os"
+
+// AppDirs builds paths for a single application, by joining the application
+// name onto the XDG base directories. For example:
+//
+//	a := xdg.App("dromi")
+//	a.UserConfig("settings.toml") // e.g. ~/.config/dromi/settings.toml
+//	a.CacheDir()                  // e.g. ~/.cache/dromi
+//
+// The methods of AppDirs correspond to those of BaseDirs, so the Open*
+// methods create the application directory if necessary.
+//
+// An application can have several parallel configurations, called profiles;
+// see WithProfile. Files can also be overridden per host; see WithHost.
+type AppDirs struct {
+	name    string
+	profile string
+	host    string
+	b       *BaseDirs
+}
+
+// App returns the AppDirs for the application name, which uses the default
+// BaseDirs of the package.
+func App(name string) *AppDirs { return &AppDirs{name: name} }
+
+// App returns the AppDirs for the application name, which uses b.
+func (b *BaseDirs) App(name string) *AppDirs { return &AppDirs{name: name, b: b} }
+
+// Name returns the name of the application.
+func (a *AppDirs) Name() string { return a.name }
+
+// Profile returns the name of the profile, or "" if a has none.
+func (a *AppDirs) Profile() string { return a.profile }
+
+// WithProfile returns a copy of a that uses the profile name, which is kept
+// in the subdirectory "profiles/name" of the application directory, e.g.
+// ~/.config/dromi/profiles/work. If name is "", the copy has no profile.
+//
+// Files are written to the profile directory. When searching or merging,
+// files in the profile directory take precedence over files in the
+// application directory: first the profile file is searched for in all base
+// directories, and then the default file.
+func (a *AppDirs) WithProfile(name string) *AppDirs {
+	c := *a
+	c.profile = name
+	return &c
+}
+
+// WithHost returns a copy of a that searches for host-specific variants of
+// files before the files themselves, which is useful if the user base
+// directories are shared across machines. The variant of a file has the
+// name of the host as additional extension, e.g. config.toml.myhost. If
+// host is "", the copy does not search for host-specific variants.
+//
+// Usually host is the result of os.Hostname. Variants are only searched for
+// and merged; files are still written without the extension.
+func (a *AppDirs) WithHost(host string) *AppDirs {
+	c := *a
+	c.host = host
+	return &c
+}
+
+// Host returns the name of the host, or "" if a does not search for
+// host-specific variants.
+func (a *AppDirs) Host() string { return a.host }
+
+// dirs returns the BaseDirs that a uses. If a was created with App, this is
+// the current default, so that Reload is respected.
+func (a *AppDirs) dirs() *BaseDirs {
+	if a.b == nil {
+		return defaults()
+	}
+	return a.b
+}
+
+// The paths of a are concatenated rather than joined with path.Join, so that
+// a ".." element in file cannot be cleaned away, and join rejects the path.
+
+// dir returns the directory of a relative to a base directory.
+func (a *AppDirs) dir() string {
+	if a.profile == "" {
+		return a.name
+	}
+	return a.name + "/profiles/" + a.profile
+}
+
+// file returns the path of file relative to a base directory, which is used
+// for writing.
+func (a *AppDirs) file(file string) string { return a.dir() + "/" + file }
+
+// files returns the paths of file relative to a base directory that are
+// searched for, in order of precedence.
+func (a *AppDirs) files(file string) []string {
+	fs := []string{a.file(file)}
+	if a.profile != "" {
+		fs = append(fs, a.name+"/"+file)
+	}
+	if a.host == "" {
+		return fs
+	}
+	hs := make([]string, 0, 2*len(fs))
+	for _, f := range fs {
+		hs = append(hs, f+"."+a.host, f)
+	}
+	return hs
+}
+
+func (a *AppDirs) Dir(category string) string { return a.dirs().User(category, a.dir()) }
+func (a *AppDirs) User(category, file string) string {
+	return a.dirs().User(category, a.file(file))
+}
+
+func (a *AppDirs) Find(category, file string) string {
+	b := a.dirs()
+	for _, f := range a.files(file) {
+		if p := b.Find(category, f); p != "" {
+			return p
+		}
+	}
+	return ""
+}
+func (a *AppDirs) FindAll(category, file string) []string {
+	b := a.dirs()
+	var ps []string
+	for _, f := range a.files(file) {
+		ps = append(ps, b.FindAll(category, f)...)
+	}
+	return ps
+}
+
+func (a *AppDirs) Merge(category, file string, f MergeFunc) error {
+	if _, ok := a.dirs().category(category); !ok {
+		return ErrUnknownCategory
+	}
+	return mergeFiles(a.FindAll(category, file), f)
+}
+func (a *AppDirs) MergeR(category, file string, f MergeFunc) error {
+	if _, ok := a.dirs().category(category); !ok {
+		return ErrUnknownCategory
+	}
+	return mergeFiles(reverse(a.FindAll(category, file)), f)
+}
+
+func (a *AppDirs) Open(category, file string, flag int) (*os.File, error) {
+	return a.dirs().Open(category, a.file(file), flag)
+}
+func (a *AppDirs) OpenFile(category, file string, flag int, perm os.FileMode) (*os.File, error) {
+	return a.dirs().OpenFile(category, a.file(file), flag, perm)
+}
+
+func (a *AppDirs) ConfigDir() string  { return a.Dir("config") }
+func (a *AppDirs) DataDir() string    { return a.Dir("data") }
+func (a *AppDirs) CacheDir() string   { return a.Dir("cache") }
+func (a *AppDirs) StateDir() string   { return a.Dir("state") }
+func (a *AppDirs) RuntimeDir() string { return a.Dir("runtime") }
+
+func (a *AppDirs) UserConfig(file string) string  { return a.User("config", file) }
+func (a *AppDirs) UserData(file string) string    { return a.User("data", file) }
+func (a *AppDirs) UserCache(file string) string   { return a.User("cache", file) }
+func (a *AppDirs) UserState(file string) string   { return a.User("state", file) }
+func (a *AppDirs) UserRuntime(file string) string { return a.User("runtime", file) }
+
+func (a *AppDirs) FindConfig(file string) string      { return a.Find("config", file) }
+func (a *AppDirs) FindData(file string) string        { return a.Find("data", file) }
+func (a *AppDirs) FindCache(file string) string       { return a.Find("cache", file) }
+func (a *AppDirs) FindState(file string) string       { return a.Find("state", file) }
+func (a *AppDirs) FindRuntime(file string) string     { return a.Find("runtime", file) }
+func (a *AppDirs) FindAllConfig(file string) []string { return a.FindAll("config", file) }
+func (a *AppDirs) FindAllData(file string) []string   { return a.FindAll("data", file) }
+
+func (a *AppDirs) MergeConfig(file string, f MergeFunc) error  { return a.Merge("config", file, f) }
+func (a *AppDirs) MergeConfigR(file string, f MergeFunc) error { return a.MergeR("config", file, f) }
+func (a *AppDirs) MergeData(file string, f MergeFunc) error    { return a.Merge("data", file, f) }
+func (a *AppDirs) MergeDataR(file string, f MergeFunc) error   { return a.MergeR("data", file, f) }
+func (a *AppDirs) MergeState(file string, f MergeFunc) error   { return a.Merge("state", file, f) }
+func (a *AppDirs) MergeCache(file string, f MergeFunc) error   { return a.Merge("cache", file, f) }
+func (a *AppDirs) MergeRuntime(file string, f MergeFunc) error { return a.Merge("runtime", file, f) }
+
+func (a *AppDirs) OpenConfig(file string, flag int) (*os.File, error) {
+	return a.Open("config", file, flag)
+}
+func (a *AppDirs) OpenData(file string, flag int) (*os.File, error) {
+	return a.Open("data", file, flag)
+}
+func (a *AppDirs) OpenCache(file string, flag int) (*os.File, error) {
+	return a.Open("cache", file, flag)
+}
+func (a *AppDirs) OpenState(file string, flag int) (*os.File, error) {
+	return a.Open("state", file, flag)
+}
+func (a *AppDirs) OpenRuntime(file string, flag int) (*os.File, error) {
+	return a.Open("runtime", file, flag)
+}
+
+func (a *AppDirs) OpenConfigFile(file string, flag int, perm os.FileMode) (*os.File, error) {
+	return a.OpenFile("config", file, flag, perm)
+}
+func (a *AppDirs) OpenDataFile(file string, flag int, perm os.FileMode) (*os.File, error) {
+	return a.OpenFile("data", file, flag, perm)
+}
+func (a *AppDirs) OpenCacheFile(file string, flag int, perm os.FileMode) (*os.File, error) {
+	return a.OpenFile("cache", file, flag, perm)
+}
+func (a *AppDirs) OpenStateFile(file string, flag int, perm os.FileMode) (*os.File, error) {
+	return a.OpenFile("state", file, flag, perm)
+}
+func (a *AppDirs) OpenRuntimeFile(file string, flag int, perm os.FileMode) (*os.File, error) {
+	return a.OpenFile("runtime", file, flag, perm)
+}
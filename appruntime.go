@@ -0,0 +1,59 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"io/fs"
+	"net"
+	"os"
+)
+
+// The runtime methods of AppDirs correspond to those of BaseDirs, but keep
+// their files in the application directory in RuntimeDir, e.g.
+// /run/user/1000/dromi, so that applications that share the runtime
+// directory of the user cannot collide. The application directory is
+// created with mode 0700 as necessary; use EnsureRuntimeDir("") to create
+// it explicitly and get its path.
+
+func (a *AppDirs) ListenRuntimeSocket(file string) (net.Listener, error) {
+	return a.dirs().ListenRuntimeSocket(a.file(file))
+}
+func (a *AppDirs) DialRuntimeSocket(file string) (net.Conn, error) {
+	return a.dirs().DialRuntimeSocket(a.file(file))
+}
+func (a *AppDirs) RuntimeFIFO(file string, perm fs.FileMode) (string, error) {
+	return a.dirs().RuntimeFIFO(a.file(file), perm)
+}
+func (a *AppDirs) WritePIDFile(name string) (release func(), err error) {
+	return a.dirs().WritePIDFile(a.file(name))
+}
+func (a *AppDirs) ReadPIDFile(name string) (pid int, running bool, err error) {
+	return a.dirs().ReadPIDFile(a.file(name))
+}
+
+// AcquireInstanceLock acquires the instance lock of the application, as
+// BaseDirs.AcquireInstanceLock does. If a has a profile, each profile has
+// its own lock, so that instances with different profiles can run at once.
+func (a *AppDirs) AcquireInstanceLock() (*InstanceLock, error) {
+	return a.dirs().AcquireInstanceLock(a.dir())
+}
+func (a *AppDirs) SendInstanceMessage(msg string) error {
+	return a.dirs().SendInstanceMessage(a.dir(), msg)
+}
+
+func (a *AppDirs) CreateRuntimeTemp(pattern string) (*os.File, error) {
+	dir, err := a.EnsureRuntimeDir("")
+	if err != nil {
+		return nil, err
+	}
+	return a.dirs().createTemp(dir, pattern)
+}
+func (a *AppDirs) MkdirRuntimeTemp(pattern string) (string, error) {
+	dir, err := a.EnsureRuntimeDir("")
+	if err != nil {
+		return "", err
+	}
+	return a.dirs().mkdirTemp(dir, pattern)
+}
@@ -0,0 +1,14 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+//go:build !linux
+
+package xdg
+
+import (
+	"io/fs"
+	"time"
+)
+
+func fileAtime(fi fs.FileInfo) (time.Time, bool) { return time.Time{}, false }
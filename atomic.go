@@ -0,0 +1,128 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// AtomicFile is a file that is written atomically and durably: the content
+// is staged in a temporary file in the same directory, which Close syncs to
+// disk and renames to the target, before syncing the directory as well.
+// Readers therefore see either the old or the new file, even if the program
+// or system crashes while writing.
+type AtomicFile struct {
+	// Backup, if not nil, makes Close preserve the previous version of the
+	// file as it specifies before replacing it.
+	Backup *Backup
+
+	b    *BaseDirs // owner of the files that are created
+	tmp  *os.File
+	name string
+	done bool
+}
+
+// AtomicWriter returns an AtomicFile for file in the user base directory
+// of category, creating the directories leading to it if necessary. If the
+// file already exists, the new file gets its permissions; otherwise it gets
+// 0600 for runtime files and 0644 for others.
+//
+// The caller must call either Close, to replace the file, or Abort, to keep
+// the file as it was. Calling Abort after Close has no effect, so Abort can
+// be deferred.
+func (b *BaseDirs) AtomicWriter(category, file string) (*AtomicFile, error) {
+	d, ok := b.category(category)
+	if !ok {
+		return nil, ErrUnknownCategory
+	}
+	if d.home == "" {
+		return nil, errUnresolved(d.env)
+	}
+	p := join(d.home, file)
+	if p == "" {
+		return nil, errInvalidFile("write", file)
+	}
+	if err := b.mkdirAll(filepath.Dir(p), dirPerm(category)); err != nil {
+		return nil, err
+	}
+	perm := dirPerm(category) &^ 0111
+	if fi, err := os.Stat(p); err == nil {
+		perm = fi.Mode().Perm()
+	}
+	return b.newAtomicFile(p, perm)
+}
+
+func AtomicWriter(category, file string) (*AtomicFile, error) {
+	return defaults().AtomicWriter(category, file)
+}
+
+// newAtomicFile returns an AtomicFile for p, whose temporary file belongs
+// to the owner of the files of b.
+func (b *BaseDirs) newAtomicFile(p string, perm os.FileMode) (*AtomicFile, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(p), "."+filepath.Base(p)+".*")
+	if err != nil {
+		return nil, err
+	}
+	err = tmp.Chmod(perm)
+	if err == nil {
+		err = b.chown(tmp.Name())
+	}
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+	return &AtomicFile{b: b, tmp: tmp, name: p}, nil
+}
+
+// Name returns the path of the file that is replaced on Close.
+func (f *AtomicFile) Name() string { return f.name }
+
+// Write writes to the temporary file.
+func (f *AtomicFile) Write(p []byte) (int, error) {
+	if f.done {
+		return 0, os.ErrClosed
+	}
+	return f.tmp.Write(p)
+}
+
+// Close syncs the temporary file, renames it to the target, and syncs the
+// directory. If any of this fails, the temporary file is removed and the
+// target is left as it was.
+func (f *AtomicFile) Close() error {
+	if f.done {
+		return os.ErrClosed
+	}
+	f.done = true
+
+	err := f.tmp.Sync()
+	if cerr := f.tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && f.Backup != nil {
+		err = f.Backup.backup(f.b, f.name)
+	}
+	if err == nil {
+		err = os.Rename(f.tmp.Name(), f.name)
+	}
+	if err != nil {
+		os.Remove(f.tmp.Name())
+		return err
+	}
+	InvalidateCache()
+	return syncDir(filepath.Dir(f.name))
+}
+
+// Abort removes the temporary file, leaving the target as it was. If Close
+// has already been called, Abort does nothing.
+func (f *AtomicFile) Abort() error {
+	if f.done {
+		return nil
+	}
+	f.done = true
+	f.tmp.Close()
+	return os.Remove(f.tmp.Name())
+}
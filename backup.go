@@ -0,0 +1,141 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Backup specifies how the previous version of a file is preserved when it
+// is replaced, so that edits made by hand are not lost.
+type Backup struct {
+	// Dir is the directory, relative to the directory of the file, in which
+	// timestamped copies such as backups/config.toml.20060102T150405.000000000Z
+	// are kept. If Dir is "", a single copy is kept as file.bak next to file.
+	// Dir must not be absolute or contain "..", so that backups stay in the
+	// base directory; otherwise the error wraps ErrInvalidPath.
+	Dir string
+
+	// Keep is the number of timestamped copies that are kept in Dir; older
+	// copies are removed. If Keep is 0, all copies are kept.
+	Keep int
+}
+
+// backupTime is the layout of the timestamp of backups, which sorts
+// lexically in chronological order.
+const backupTime = "20060102T150405.000000000Z"
+
+// backup preserves the file p, if it exists, as bk specifies. The files and
+// directories that it creates belong to the owner of the files of b.
+func (bk *Backup) backup(b *BaseDirs, p string) error {
+	if bk.Dir != "" && !validFile(bk.Dir) {
+		return errInvalidFile("backup", bk.Dir)
+	}
+	if _, err := os.Lstat(p); os.IsNotExist(err) {
+		return nil
+	}
+	if bk.Dir == "" {
+		return b.linkOrCopy(p, p+".bak")
+	}
+
+	dir := filepath.Join(filepath.Dir(p), bk.Dir)
+	if err := b.mkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	prefix := filepath.Base(p) + "."
+	err := b.linkOrCopy(p, filepath.Join(dir, prefix+time.Now().UTC().Format(backupTime)))
+	if err != nil || bk.Keep <= 0 {
+		return err
+	}
+
+	es, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var old []string
+	for _, e := range es {
+		if strings.HasPrefix(e.Name(), prefix) && len(e.Name()) == len(prefix)+len(backupTime) {
+			old = append(old, e.Name())
+		}
+	}
+	sort.Strings(old)
+	for len(old) > bk.Keep {
+		if err := os.Remove(filepath.Join(dir, old[0])); err != nil {
+			return err
+		}
+		old = old[1:]
+	}
+	return nil
+}
+
+// linkOrCopy makes dst a hard link to src, replacing dst. Since the file
+// that src refers to is replaced by a rename rather than modified, a link
+// suffices as backup. If the file system does not support hard links, src
+// is copied instead.
+func (b *BaseDirs) linkOrCopy(src, dst string) error {
+	if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if os.Link(src, dst) == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	fi, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, fi.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	err = b.chown(dst)
+	if err == nil {
+		_, err = io.Copy(out, in)
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// WriteFileBackup is like WriteFile, but preserves the previous version of
+// file as bk specifies before replacing it.
+func (b *BaseDirs) WriteFileBackup(category, file string, data []byte, perm os.FileMode, bk Backup) error {
+	f, err := b.AtomicWriter(category, file)
+	if err != nil {
+		return err
+	}
+	if err := f.tmp.Chmod(perm); err != nil {
+		f.Abort()
+		return err
+	}
+	f.Backup = &bk
+	if _, err := f.Write(data); err != nil {
+		f.Abort()
+		return err
+	}
+	return f.Close()
+}
+
+func (b *BaseDirs) WriteConfigFileBackup(file string, data []byte, perm os.FileMode, bk Backup) error {
+	return b.WriteFileBackup("config", file, data, perm, bk)
+}
+
+func WriteFileBackup(category, file string, data []byte, perm os.FileMode, bk Backup) error {
+	return defaults().WriteFileBackup(category, file, data, perm, bk)
+}
+func WriteConfigFileBackup(file string, data []byte, perm os.FileMode, bk Backup) error {
+	return defaults().WriteConfigFileBackup(file, data, perm, bk)
+}
@@ -0,0 +1,527 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BaseDirs contains the XDG base directories resolved for one environment.
+//
+// The package-level variables and functions use a default BaseDirs, which
+// is resolved from the process environment by Init. If you need to resolve
+// directories for a different environment (for example in tests, or for
+// another user), create your own with New or NewFromEnviron.
+//
+// The fields should be treated as read-only once the BaseDirs is in use.
+type BaseDirs struct {
+	// ConfigHome is a single base directory relative to which user-specific
+	// configuration files should be written.
+	ConfigHome string
+
+	// DataHome is a single base directory relative to which user-specific data
+	// files should be written.
+	DataHome string
+
+	// CacheHome is a single base directory relative to which user-specific
+	// non-essential (cached) data should be written.
+	CacheHome string
+
+	// StateHome is a single base directory relative to which user-specific
+	// state data should be written.
+	StateHome string
+
+	// BinHome is a single base directory in which user-specific executables
+	// may be stored.
+	BinHome string
+
+	// RuntimeDir is a single base directory relative to which user-specific
+	// runtime files and other file objects should be placed.
+	RuntimeDir string
+
+	// RuntimeSource describes how RuntimeDir was determined, in particular
+	// whether it is a replacement for an unset $XDG_RUNTIME_DIR.
+	RuntimeSource RuntimeSource
+
+	// SudoUser is the name of the user who invoked sudo, if the directories
+	// were resolved for that user; see Sudo.
+	SudoUser string
+
+	// Service is true if the directories were resolved for a system
+	// service; see Service.
+	Service bool
+
+	// TempDir is the directory for temporary files, in which fallback
+	// directories are created, such as the replacement for an unset
+	// $XDG_RUNTIME_DIR. It is $TMPDIR if that is set and absolute, or else
+	// the default of the system, e.g. /tmp.
+	TempDir string
+
+	// Home is the home directory of the user, on which the defaults of the
+	// user base directories are based, or "" if it could not be found.
+	Home string
+
+	// HomeSource describes where Home was found. If $HOME is not set, the
+	// home directory of the user of the process is looked up with os/user,
+	// before FallbackRoot is used.
+	HomeSource HomeSource
+
+	// HomeFallback is FallbackRoot if it was used in place of an invalid
+	// $HOME, else "".
+	HomeFallback string
+
+	// Sandbox describes the application sandbox that the process runs in,
+	// which can change the defaults of the base directories.
+	Sandbox SandboxInfo
+
+	// ConfigDirs is a set of preference ordered base directories relative to
+	// which configuration files should be searched.
+	ConfigDirs []string
+
+	// DataDirs is a set of preference ordered base directories relative to
+	// which data files should be searched.
+	DataDirs []string
+
+	// Errors contains all errors that occurred during resolution.
+	Errors []error
+
+	// custom contains the registered categories, resolved together with
+	// the other directories.
+	custom map[string]categoryDirs
+
+	// uid and gid are the owner of created files if SudoUser is set.
+	uid, gid int
+
+	// getenv is the function with which b was resolved, for Validate and
+	// Getenv.
+	getenv func(string) string
+
+	// app is the name of the application, as set by WithAppName.
+	app string
+}
+
+// New returns a BaseDirs resolved from the environment, as read by Getenv,
+// and configured by opts, e.g.
+//
+//	b := xdg.New(xdg.WithLookupEnv(lookup), xdg.WithStrictness(xdg.Lenient))
+//
+// Without options, the package variables Getenv, Expand, and Strictness
+// are used.
+func New(opts ...Option) *BaseDirs {
+	o := newOptions(Getenv)
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return resolveWith(o)
+}
+
+// NewFromEnviron returns a BaseDirs resolved from env, which has the same
+// "key=value" format as os.Environ. The process environment is not read,
+// so env can be that of another session or user, e.g. from
+// /proc/PID/environ. If a key occurs more than once, the last value is used.
+//
+// The home directory is only taken from env: if $HOME is not set, the
+// current user is not looked up as by New, and, as when $HOME is relative,
+// the error wraps ErrInvalidHome unless FallbackRoot is set. DetectRuntimeDir
+// is ignored, since /run/user/$UID belongs to the user of the process. Apart
+// from env, resolution still depends on the package variables FallbackRoot,
+// Sudo, Native, Service, Expand, and Strictness, and on the following state
+// of the process: its user ID, which names the replacement runtime directory
+// if $XDG_RUNTIME_DIR is not set; whether it runs as root, for Sudo and
+// ServiceAuto; the user database, for the user named by $SUDO_USER; and the
+// file /.flatpak-info.
+//
+// The returned error joins the entries of env that have no "=" and the
+// errors that occurred during resolution, which are available from Err as
+// well. The BaseDirs is returned even if there is an error.
+func NewFromEnviron(env []string) (*BaseDirs, error) {
+	var errs []error
+	m := make(map[string]string, len(env))
+	for _, kv := range env {
+		i := strings.IndexByte(kv, '=')
+		if i < 0 {
+			errs = append(errs, fmt.Errorf("invalid environment entry %q", kv))
+			continue
+		}
+		m[kv[:i]] = kv[i+1:]
+	}
+	o := newOptions(func(key string) string { return m[key] })
+	o.environ = true
+	b := resolveWith(o)
+	return b, errors.Join(append(errs, b.Errors...)...)
+}
+
+// Getenv returns the value of the environment variable key in the
+// environment from which b was resolved, such as that given to New with
+// WithGetenv or to NewFromEnviron. If b was not resolved by this package,
+// the package variable Getenv is used.
+func (b *BaseDirs) Getenv(key string) string {
+	if b.getenv == nil {
+		return Getenv(key)
+	}
+	return b.getenv(key)
+}
+
+// Err returns all errors that occurred during resolution, joined into one
+// error with errors.Join, or nil if there were none.
+func (b *BaseDirs) Err() error { return errors.Join(b.Errors...) }
+
+// ErrFor returns the errors that occurred during resolution of the
+// environment variable env, joined into one error, or nil if there were none.
+// For example, ErrFor("XDG_RUNTIME_DIR") returns a *VarError if the runtime
+// directory could not be resolved, and ErrFor("HOME") returns ErrInvalidHome
+// if the home directory is invalid.
+func (b *BaseDirs) ErrFor(env string) error {
+	var errs []error
+	for _, err := range b.Errors {
+		var v *VarError
+		if errors.As(err, &v) && v.Var == env || env == "HOME" && err == ErrInvalidHome {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// resolver reads the XDG environment variables with getenv and collects
+// any errors that occur.
+type resolver struct {
+	getenv func(string) string
+	expand bool
+	level  Level
+	home   string
+	errs   []error
+}
+
+func resolveWith(o options) *BaseDirs {
+	getenv := o.getenv
+	r := &resolver{getenv: getenv, expand: o.expand, level: o.level}
+	home, src := homeDir(getenv)
+	su, sudo := lookupSudoUser(getenv)
+	if sudo {
+		home, src = su.home, HomeSudo
+	}
+	if o.home != "" {
+		home, src = o.home, HomeOption
+	}
+	var fallback string
+	if home == "" && !o.environ {
+		home, src = currentUserHome(), HomeUser
+	}
+	if !filepath.IsAbs(home) {
+		home = ""
+		if filepath.IsAbs(FallbackRoot) {
+			home, src, fallback = FallbackRoot, HomeFallbackRoot, FallbackRoot
+		} else {
+			src = HomeNone
+			r.errs = append(r.errs, ErrInvalidHome)
+		}
+	}
+	r.home = home
+
+	def := platformDefaults(getenv)
+	b := &BaseDirs{
+		getenv:       getenv,
+		app:          o.app,
+		Home:         home,
+		HomeSource:   src,
+		HomeFallback: fallback,
+		TempDir:      tempDir(getenv),
+		Sandbox:      detectSandbox(getenv, home),
+	}
+	b.Sandbox.adjust(&def)
+	if sudo {
+		b.SudoUser, b.uid, b.gid = su.name, su.uid, su.gid
+		def.runtimeDir = filepath.Join(b.TempDir, fmt.Sprintf("xdg-%d", su.uid))
+	}
+	if b.Service = Service.enabled(); b.Service {
+		def = serviceDefaults(getenv)
+	}
+	b.ConfigHome = r.path("XDG_CONFIG_HOME", def.configHome)
+	b.DataHome = r.path("XDG_DATA_HOME", def.dataHome)
+	b.CacheHome = r.path("XDG_CACHE_HOME", def.cacheHome)
+	b.StateHome = r.path("XDG_STATE_HOME", def.stateHome)
+	b.BinHome = r.path("XDG_BIN_HOME", def.binHome)
+	b.RuntimeDir = r.path("XDG_RUNTIME_DIR", def.runtimeDir)
+	if r.getenv("XDG_RUNTIME_DIR") == "" {
+		// /run/user/$UID is only looked at if detection is enabled.
+		var dir string
+		if DetectRuntimeDir && !o.environ && !b.Service {
+			dir = systemdRuntimeDir()
+		}
+		if b.Service {
+			b.RuntimeSource = RuntimeService
+		} else if dir != "" {
+			b.RuntimeDir, b.RuntimeSource = dir, RuntimeSystemd
+		} else if r.level == Strict {
+			b.RuntimeDir = ""
+			r.errs = append(r.errs, &VarError{Var: "XDG_RUNTIME_DIR", Reason: ErrNotSet})
+		} else {
+			b.RuntimeSource = RuntimeFallback
+			if OnRuntimeFallback != nil {
+				OnRuntimeFallback(b.RuntimeDir)
+			}
+		}
+	}
+	b.ConfigDirs = r.paths("XDG_CONFIG_DIRS", def.configDirs)
+	b.DataDirs = r.paths("XDG_DATA_DIRS", def.dataDirs)
+	b.custom = r.customCategories()
+	b.Errors = r.errs
+	if b.Errors == nil {
+		b.Errors = []error{}
+	}
+	return b
+}
+
+func (r *resolver) path(env, def string) string {
+	x := r.expandValue(r.getenv(env))
+
+	if x == "" {
+		if strings.Contains(def, "$HOME") {
+			if r.home != "" {
+				x = strings.Replace(def, "$HOME", r.home, -1)
+			}
+		} else {
+			x = def
+		}
+	}
+
+	// The XDG specification states:
+	//
+	//  All paths set in these environment variables must be absolute. If an
+	//  implementation encounters a relative path in any of these variables it
+	//  should consider the path invalid and ignore it.
+	//
+	// Lenient and Permissive deviate from this on purpose.
+	if filepath.IsAbs(x) {
+		return x
+	}
+	if p, ok := r.level.relative(x); ok && x != "" {
+		return p
+	}
+	if x == "" && def != "" {
+		// The default depends on $HOME, which is invalid.
+		r.errs = append(r.errs, &VarError{Var: env, Reason: ErrInvalidHome})
+	} else if x == "" {
+		r.errs = append(r.errs, &VarError{Var: env, Reason: ErrNotSet})
+	} else {
+		r.errs = append(r.errs, &VarError{Var: env, Value: x, Reason: ErrNotAbsolute})
+	}
+	return ""
+}
+
+func (r *resolver) paths(env, def string) []string {
+	xs := r.getenv(env)
+
+	if xs == "" {
+		xs = def
+	}
+	if xs == "" {
+		return nil
+	}
+
+	fs, bad := splitDirList(xs, r.expandValue, r.level)
+	for _, x := range bad {
+		r.errs = append(r.errs, &VarError{Var: env, Value: x, Reason: ErrNotAbsolute})
+	}
+	return fs
+}
+
+// SplitDirList splits s, which is a list of directories separated by
+// os.PathListSeparator, such as the value of $XDG_DATA_DIRS, in the same way
+// as the package does. Empty elements, including those of leading and
+// trailing separators, are skipped. Relative paths are dropped, as the
+// specification requires, and an error wrapping ErrNotAbsolute is returned
+// for each of them. Applications can use it for variables of their own that
+// should behave like the XDG variables. Both rules can be relaxed with
+// Strictness.
+func SplitDirList(s string) ([]string, []error) {
+	dirs, bad := splitDirList(s, func(x string) string { return x }, Strictness)
+	var errs []error
+	for _, x := range bad {
+		errs = append(errs, fmt.Errorf("%w: %q", ErrNotAbsolute, x))
+	}
+	return dirs, errs
+}
+
+// splitDirList splits the list s and returns the directories in it and the
+// relative ones that level rejects separately, after applying expand to each
+// element.
+func splitDirList(s string, expand func(string) string, level Level) (dirs, bad []string) {
+	for _, x := range strings.Split(s, string(os.PathListSeparator)) {
+		if x == "" {
+			if level != Permissive {
+				continue
+			}
+			x = "."
+		}
+		x = expand(x)
+		// See comment in path.
+		if filepath.IsAbs(x) {
+			dirs = append(dirs, x)
+		} else if p, ok := level.relative(x); ok && x != "" {
+			dirs = append(dirs, p)
+		} else {
+			bad = append(bad, x)
+		}
+	}
+	return dirs, bad
+}
+
+// expandValue expands a leading ~ and environment variables in x, which
+// is read from the environment, if r.expand is true. Variables are looked
+// up with r.getenv; ~ is replaced by $HOME, if that is valid.
+func (r *resolver) expandValue(x string) string {
+	if !r.expand {
+		return x
+	}
+	if r.home != "" && (x == "~" || strings.HasPrefix(x, "~/")) {
+		x = r.home + x[1:]
+	}
+	return os.Expand(x, r.getenv)
+}
+
+// combine x and xs to a new slice, where x is in the front.
+// If x is empty, it is left out.
+func combine(x string, xs []string) []string {
+	ns := make([]string, 0, len(xs)+1)
+	if x != "" {
+		ns = append(ns, x)
+	}
+	return append(ns, xs...)
+}
+
+// The *Paths methods return the preference ordered list of base directories
+// that are searched for each type of file, with the user base directory first.
+// Directories that could not be resolved are left out. A new slice is
+// returned on every call, so it may be modified by the caller.
+
+func (b *BaseDirs) ConfigPaths() []string  { return combine(b.ConfigHome, b.ConfigDirs) }
+func (b *BaseDirs) DataPaths() []string    { return combine(b.DataHome, b.DataDirs) }
+func (b *BaseDirs) CachePaths() []string   { return combine(b.CacheHome, nil) }
+func (b *BaseDirs) StatePaths() []string   { return combine(b.StateHome, nil) }
+func (b *BaseDirs) RuntimePaths() []string { return combine(b.RuntimeDir, nil) }
+
+func (b *BaseDirs) UserConfig(file string) string  { return join(b.ConfigHome, file) }
+func (b *BaseDirs) UserData(file string) string    { return join(b.DataHome, file) }
+func (b *BaseDirs) UserCache(file string) string   { return join(b.CacheHome, file) }
+func (b *BaseDirs) UserState(file string) string   { return join(b.StateHome, file) }
+func (b *BaseDirs) UserRuntime(file string) string { return join(b.RuntimeDir, file) }
+
+func (b *BaseDirs) FindConfig(file string) string  { return find(file, b.ConfigPaths()) }
+func (b *BaseDirs) FindData(file string) string    { return find(file, b.DataPaths()) }
+func (b *BaseDirs) FindCache(file string) string   { return find(file, b.CachePaths()) }
+func (b *BaseDirs) FindState(file string) string   { return find(file, b.StatePaths()) }
+func (b *BaseDirs) FindRuntime(file string) string { return find(file, b.RuntimePaths()) }
+func (b *BaseDirs) FindAllConfig(file string) []string {
+	return findAll(file, b.ConfigPaths())
+}
+func (b *BaseDirs) FindAllData(file string) []string { return findAll(file, b.DataPaths()) }
+
+func (b *BaseDirs) MergeConfig(file string, f MergeFunc) error {
+	return merge(file, f, b.ConfigPaths())
+}
+func (b *BaseDirs) MergeConfigR(file string, f MergeFunc) error {
+	return mergeR(file, f, b.ConfigPaths())
+}
+func (b *BaseDirs) MergeData(file string, f MergeFunc) error {
+	return merge(file, f, b.DataPaths())
+}
+func (b *BaseDirs) MergeDataR(file string, f MergeFunc) error {
+	return mergeR(file, f, b.DataPaths())
+}
+func (b *BaseDirs) MergeState(file string, f MergeFunc) error {
+	return merge(file, f, b.StatePaths())
+}
+func (b *BaseDirs) MergeCache(file string, f MergeFunc) error {
+	return merge(file, f, b.CachePaths())
+}
+func (b *BaseDirs) MergeRuntime(file string, f MergeFunc) error {
+	return merge(file, f, b.RuntimePaths())
+}
+
+func (b *BaseDirs) OpenConfig(file string, flag int) (*os.File, error) {
+	return b.OpenConfigFile(file, flag, 0644)
+}
+func (b *BaseDirs) OpenData(file string, flag int) (*os.File, error) {
+	return b.OpenDataFile(file, flag, 0644)
+}
+func (b *BaseDirs) OpenCache(file string, flag int) (*os.File, error) {
+	return b.OpenCacheFile(file, flag, 0644)
+}
+func (b *BaseDirs) OpenState(file string, flag int) (*os.File, error) {
+	return b.OpenStateFile(file, flag, 0644)
+}
+func (b *BaseDirs) OpenRuntime(file string, flag int) (*os.File, error) {
+	return b.OpenRuntimeFile(file, flag, 0600)
+}
+
+func (b *BaseDirs) OpenConfigFile(file string, flag int, perm os.FileMode) (*os.File, error) {
+	return b.open(b.ConfigHome, "XDG_CONFIG_HOME", file, flag, 0755, perm)
+}
+func (b *BaseDirs) OpenDataFile(file string, flag int, perm os.FileMode) (*os.File, error) {
+	return b.open(b.DataHome, "XDG_DATA_HOME", file, flag, 0755, perm)
+}
+func (b *BaseDirs) OpenCacheFile(file string, flag int, perm os.FileMode) (*os.File, error) {
+	return b.open(b.CacheHome, "XDG_CACHE_HOME", file, flag, 0755, perm)
+}
+func (b *BaseDirs) OpenStateFile(file string, flag int, perm os.FileMode) (*os.File, error) {
+	return b.open(b.StateHome, "XDG_STATE_HOME", file, flag, 0755, perm)
+}
+func (b *BaseDirs) OpenRuntimeFile(file string, flag int, perm os.FileMode) (*os.File, error) {
+	if err := b.prepareRuntimeDir(); err != nil {
+		return nil, err
+	}
+	return b.open(b.RuntimeDir, "XDG_RUNTIME_DIR", file, flag, 0700, perm)
+}
+
+// prepareRuntimeDir creates RuntimeDir if it does not exist, and makes sure
+// that it belongs to the user.
+func (b *BaseDirs) prepareRuntimeDir() error {
+	if b.RuntimeDir == "" {
+		return errUnresolved("XDG_RUNTIME_DIR")
+	}
+	if b.RuntimeSource == RuntimeService {
+		// /run belongs to the system; it is neither created nor changed,
+		// so that a service that does not run as root can use it as well.
+		_, err := os.Stat(b.RuntimeDir)
+		return err
+	}
+
+	fi, err := os.Stat(b.RuntimeDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			err = b.mkdirAll(b.RuntimeDir, os.ModeDir|0700)
+			if err != nil {
+				return err
+			}
+			fi, err = os.Stat(b.RuntimeDir)
+			if err != nil {
+				// This really should never happen, but you never know!
+				return err
+			}
+		} else {
+			return err
+		}
+	}
+
+	uid, gid := b.owner()
+	err = os.Chown(b.RuntimeDir, uid, gid)
+	if err != nil {
+		return err
+	}
+
+	// A replacement directory may have been created by someone else, or
+	// with a different umask; make sure that only the user can access it.
+	if b.RuntimeSource == RuntimeFallback && fi.Mode().Perm() != 0700 {
+		if err := os.Chmod(b.RuntimeDir, 0700); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSplitDirList(t *testing.T) {
+	windows := runtime.GOOS == "windows"
+	tests := []struct {
+		dir     string
+		unixAbs bool // whether dir is absolute on other systems
+		winAbs  bool // whether dir is absolute on Windows
+	}{
+		{`/usr/share`, true, false},
+		{`/usr\share`, true, false},
+		{`\usr\share`, false, false},
+		{`usr/share`, false, false},
+		{`usr\share`, false, false},
+		{`C:\ProgramData`, false, true},
+		{`C:/ProgramData`, false, true},
+		{`C:/Program Data\xdg`, false, true},
+		{`C:ProgramData`, false, false},
+		{`\\server\share\xdg`, false, true},
+		{`//server/share/xdg`, true, true},
+		{`\\server/share\xdg`, false, true},
+	}
+	for _, tt := range tests {
+		want := tt.unixAbs
+		if windows {
+			want = tt.winAbs
+		}
+		// On other systems, the ':' of a drive letter separates elements.
+		if !windows && strings.ContainsRune(tt.dir, os.PathListSeparator) {
+			continue
+		}
+		dirs, bad := splitDirList(tt.dir, func(x string) string { return x }, Standard)
+		if got := len(dirs) == 1 && dirs[0] == tt.dir; got != want {
+			t.Errorf("splitDirList(%q) = %q, %q; want absolute %v", tt.dir, dirs, bad, want)
+		}
+		if !want && !slices.Equal(bad, []string{tt.dir}) {
+			t.Errorf("splitDirList(%q) rejected %q; want %q", tt.dir, bad, tt.dir)
+		}
+	}
+
+	// A list mixes absolute, relative, and empty elements.
+	abs1, abs2 := "/usr/local/share", "/usr/share"
+	if windows {
+		abs1, abs2 = `C:\ProgramData`, `\\server\share`
+	}
+	sep := string(os.PathListSeparator)
+	list := sep + abs1 + sep + sep + "share" + sep + abs2 + sep
+	dirs, bad := splitDirList(list, func(x string) string { return x }, Standard)
+	if !slices.Equal(dirs, []string{abs1, abs2}) || !slices.Equal(bad, []string{"share"}) {
+		t.Errorf("splitDirList(%q) = %q, %q; want %q, %q", list, dirs, bad, []string{abs1, abs2}, []string{"share"})
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// FindExecutable returns the absolute path of the executable name in BinHome.
+// If it is not found there and usePath is true, the absolute directories in
+// the PATH variable of the environment of b are searched as well, unless
+// name contains a path separator. If no executable is found, "" is returned.
+func (b *BaseDirs) FindExecutable(name string, usePath bool) string {
+	p := b.User("bin", name)
+	if fi, err := os.Stat(p); err == nil && fi.Mode().IsRegular() && fi.Mode()&0111 != 0 {
+		return p
+	}
+	if usePath && filepath.Base(name) == name {
+		for _, dir := range filepath.SplitList(b.Getenv("PATH")) {
+			// Relative directories are skipped, as exec.LookPath does.
+			if !filepath.IsAbs(dir) {
+				continue
+			}
+			if p, err := exec.LookPath(filepath.Join(dir, name)); err == nil {
+				return p
+			}
+		}
+	}
+	return ""
+}
+
+// InstallExecutable copies the file src to BinHome as name, with the
+// permission 0755, and returns the path of the installed executable.
+// BinHome is created if necessary. An existing executable is replaced
+// atomically, so that it can be installed while it is running.
+func (b *BaseDirs) InstallExecutable(src, name string) (string, error) {
+	if b.BinHome == "" {
+		return "", errUnresolved("XDG_BIN_HOME")
+	}
+	dst := join(b.BinHome, name)
+	if dst == "" || filepath.Dir(dst) != b.BinHome {
+		return "", errInvalidFile("install", name)
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+
+	if err := b.mkdirAll(b.BinHome, 0755); err != nil {
+		return "", err
+	}
+	tmp, err := b.createTemp(b.BinHome, "."+name+".*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, in)
+	if err == nil {
+		err = tmp.Chmod(0755)
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), dst)
+	}
+	if err != nil {
+		return "", err
+	}
+	InvalidateCache()
+	return dst, nil
+}
+
+func FindExecutable(name string, usePath bool) string {
+	return defaults().FindExecutable(name, usePath)
+}
+func InstallExecutable(src, name string) (string, error) {
+	return defaults().InstallExecutable(src, name)
+}
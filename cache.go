@@ -0,0 +1,49 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+// CacheLookups enables a cache for the files that the Find*, FindAll*, and
+// Merge* functions find, so that repeated lookups of the same file, such as
+// icons in a theme, do not stat every base directory again. Lookups of files
+// that do not exist are cached as well.
+//
+// The functions of this package that create, replace, or remove files in
+// the base directories, such as WriteFile, Promote, and the Set* functions,
+// invalidate the cache. Otherwise the cache is not aware of changes to the
+// file system: call InvalidateCache after files have been created or removed
+// by other means, such as another process. Reload invalidates the cache.
+// CacheLookups is false by default.
+var CacheLookups = false
+
+// lookups maps a file and the base directories it was searched in to the
+// resulting []Hit.
+var lookups sync.Map
+
+// InvalidateCache removes all entries from the cache that CacheLookups
+// enables, so that subsequent lookups see the current file system.
+func InvalidateCache() { lookups.Clear() }
+
+func lookupKey(file string, paths []string) string {
+	return file + "\x00" + strings.Join(paths, "\x00")
+}
+
+// cachedHits returns a copy of the cached result of findHits.
+func cachedHits(file string, paths []string) ([]Hit, bool) {
+	v, ok := lookups.Load(lookupKey(file, paths))
+	if !ok {
+		return nil, false
+	}
+	return slices.Clone(v.([]Hit)), true
+}
+
+func storeHits(file string, paths []string, hs []Hit) {
+	lookups.Store(lookupKey(file, paths), slices.Clone(hs))
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// BenchmarkFind measures Find over 50 data directories, of which only the
+// last one contains the file, with and without CacheLookups.
+func BenchmarkFind(b *testing.B) {
+	root := b.TempDir()
+	dirs := make([]string, 50)
+	for i := range dirs {
+		dirs[i] = filepath.Join(root, fmt.Sprintf("data%d", i))
+		if err := os.Mkdir(dirs[i], 0755); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dirs[len(dirs)-1], "icon.png"), nil, 0644); err != nil {
+		b.Fatal(err)
+	}
+	bd, err := NewFromEnviron([]string{"HOME=" + root})
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := bd.SetDataDirs(dirs); err != nil {
+		b.Fatal(err)
+	}
+
+	for _, cache := range []bool{false, true} {
+		b.Run(fmt.Sprintf("cache=%v", cache), func(b *testing.B) {
+			defer func(old bool) { CacheLookups = old }(CacheLookups)
+			CacheLookups = cache
+			InvalidateCache()
+			for i := 0; i < b.N; i++ {
+				if bd.FindData("icon.png") == "" {
+					b.Fatal("icon.png not found")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,363 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+// Package cachedir provides a size-bounded cache of named blobs in the XDG
+// cache directory of an application, e.g. ~/.cache/dromi. When a blob is put
+// into the cache and the cache grows beyond its limit, the least recently
+// used blobs are evicted:
+//
+//	c, err := cachedir.Open("dromi", cachedir.Limit(500*cachedir.MiB))
+//	err = c.Put("covers/1234.jpg", r)
+//	rc, err := c.Get("covers/1234.jpg")
+//
+// The sizes and access times of the blobs are kept in an index file in the
+// cache directory. The index is locked while it is used, so several
+// processes can share a cache safely.
+package cachedir
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goulash/xdg"
+)
+
+// Units for Limit.
+const (
+	KiB int64 = 1 << (10 * (iota + 1))
+	MiB
+	GiB
+)
+
+// Cache is a size-bounded cache of named blobs.
+type Cache struct {
+	b     *xdg.BaseDirs
+	name  string
+	dir   string
+	limit int64
+	gzip  bool // compress blobs
+	level int  // gzip compression level
+
+	mu sync.Mutex // serializes use of the index within the process
+}
+
+// Option configures a Cache.
+type Option func(*Cache)
+
+// Limit limits the total size of the blobs in the cache to n bytes. If n is
+// not positive, which is the default, the cache is not limited.
+func Limit(n int64) Option { return func(c *Cache) { c.limit = n } }
+
+// Compress makes the cache compress blobs with gzip at the given level,
+// such as gzip.DefaultCompression, which trades CPU time for disk space on
+// caches of text. Get decompresses blobs transparently; blobs that are not
+// compressed, because they were put before compression was enabled, are
+// recognized by their missing gzip header and returned as they are. The
+// limit of the cache applies to the compressed size.
+func Compress(level int) Option {
+	return func(c *Cache) { c.gzip, c.level = true, level }
+}
+
+// Open opens the cache in the directory name of the XDG cache directory,
+// which is created if necessary.
+func Open(name string, opts ...Option) (*Cache, error) { return OpenDirs(nil, name, opts...) }
+
+// OpenDirs is like Open, but uses the cache directory of b. If b is nil,
+// the default base directories of package xdg are used.
+func OpenDirs(b *xdg.BaseDirs, name string, opts ...Option) (*Cache, error) {
+	if b == nil {
+		b = xdg.New()
+	}
+	dir, err := b.EnsureCacheDir(name)
+	if err != nil {
+		return nil, err
+	}
+	c := &Cache{b: b, name: name, dir: dir}
+	for _, o := range opts {
+		o(c)
+	}
+	return c, nil
+}
+
+// Dir returns the directory of the cache.
+func (c *Cache) Dir() string { return c.dir }
+
+// KeyFor returns a key for the blob identified by namespace and parts,
+// such as a URL and its query. The parts are hashed with SHA-256 and the
+// hash is sharded by its first two bytes, e.g. "thumbs/aa/bb/aabb...", so
+// that arbitrary strings can be used as keys without exhausting the limits
+// of the file system on names or on entries per directory. If namespace is
+// "", the key has no namespace directory.
+func (c *Cache) KeyFor(namespace string, parts ...string) string {
+	h := sha256.New()
+	io.WriteString(h, namespace)
+	for _, p := range parts {
+		// The separator keeps ("ab", "c") and ("a", "bc") apart.
+		h.Write([]byte{0})
+		io.WriteString(h, p)
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	key := sum[0:2] + "/" + sum[2:4] + "/" + sum
+	if namespace == "" {
+		return key
+	}
+	return namespace + "/" + key
+}
+
+// entry is the record of a blob in the index.
+type entry struct {
+	Size    int64     `json:"size"`
+	Used    time.Time `json:"used"`
+	Expires time.Time `json:"expires"`
+}
+
+// expired returns true if the blob has expired at time t.
+func (e *entry) expired(t time.Time) bool { return !e.Expires.IsZero() && !t.Before(e.Expires) }
+
+// index maps the keys of the blobs to their records.
+type index map[string]*entry
+
+// path returns the path of the blob key, or "" if key is invalid. Keys
+// are separated by slashes on all systems, so backslashes are rejected.
+func (c *Cache) path(key string) string {
+	if key == "" || path.IsAbs(key) || strings.HasSuffix(key, "/") || strings.Contains(key, `\`) {
+		return ""
+	}
+	for _, s := range strings.Split(key, "/") {
+		if s == "" || s == "." || s == ".." {
+			return ""
+		}
+	}
+	return filepath.Join(c.dir, "blobs", filepath.FromSlash(key))
+}
+
+func errInvalidKey(op, key string) error {
+	return &fs.PathError{Op: op, Path: key, Err: fs.ErrInvalid}
+}
+
+// Get returns the blob key, which the caller must close, and marks it as
+// used. If the cache does not contain key, the error wraps fs.ErrNotExist.
+func (c *Cache) Get(key string) (io.ReadCloser, error) {
+	p := c.path(key)
+	if p == "" {
+		return nil, errInvalidKey("get", key)
+	}
+	var f *os.File
+	err := c.update(func(idx index) error {
+		e, ok := idx[key]
+		if !ok {
+			return &fs.PathError{Op: "get", Path: key, Err: fs.ErrNotExist}
+		}
+		now := time.Now()
+		if e.expired(now) {
+			delete(idx, key)
+			os.Remove(p)
+			return &fs.PathError{Op: "get", Path: key, Err: fs.ErrNotExist}
+		}
+		var err error
+		f, err = os.Open(p)
+		if errors.Is(err, fs.ErrNotExist) {
+			// The blob was removed behind our back.
+			delete(idx, key)
+			return &fs.PathError{Op: "get", Path: key, Err: fs.ErrNotExist}
+		} else if err != nil {
+			return err
+		}
+		e.Used = now
+		return nil
+	})
+	if err != nil {
+		if f != nil {
+			f.Close()
+		}
+		return nil, err
+	}
+	if !c.gzip {
+		return f, nil
+	}
+	return decompress(f)
+}
+
+// decompress returns a reader of f that decompresses it if it starts with
+// a gzip header.
+func decompress(f *os.File) (io.ReadCloser, error) {
+	br := bufio.NewReader(f)
+	magic, _ := br.Peek(2)
+	if len(magic) < 2 || magic[0] != 0x1f || magic[1] != 0x8b {
+		return readCloser{br, f}, nil
+	}
+	zr, err := gzip.NewReader(br)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return readCloser{zr, f}, nil
+}
+
+// readCloser reads from one reader and closes another.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// Put stores the content of r as the blob key, replacing any previous blob,
+// and evicts the least recently used blobs if the cache exceeds its limit.
+// A blob that is larger than the limit by itself is stored nonetheless.
+func (c *Cache) Put(key string, r io.Reader) error { return c.put(key, r, time.Time{}) }
+
+// PutTTL is like Put, but the blob expires after ttl. Get treats an expired
+// blob as missing and deletes it, and expired blobs are evicted before any
+// others.
+func (c *Cache) PutTTL(key string, r io.Reader, ttl time.Duration) error {
+	return c.put(key, r, time.Now().Add(ttl))
+}
+
+// put stores a blob that expires at the given time, or never if it is zero.
+func (c *Cache) put(key string, r io.Reader, expires time.Time) error {
+	p := c.path(key)
+	if p == "" {
+		return errInvalidKey("put", key)
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".put.*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	n, err := c.write(tmp, r)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.update(func(idx index) error {
+		if err := os.Rename(tmp.Name(), p); err != nil {
+			return err
+		}
+		idx[key] = &entry{Size: n, Used: time.Now(), Expires: expires}
+		return c.evict(idx, key)
+	})
+}
+
+// Delete removes the blob key from the cache. Deleting a blob that does not
+// exist is not an error.
+func (c *Cache) Delete(key string) error {
+	p := c.path(key)
+	if p == "" {
+		return errInvalidKey("delete", key)
+	}
+	return c.update(func(idx index) error {
+		delete(idx, key)
+		if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	})
+}
+
+// write copies r to f, compressing it if necessary, and returns the number
+// of bytes written to f.
+func (c *Cache) write(f *os.File, r io.Reader) (int64, error) {
+	if !c.gzip {
+		return io.Copy(f, r)
+	}
+	zw, err := gzip.NewWriterLevel(f, c.level)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := io.Copy(zw, r); err != nil {
+		return 0, err
+	}
+	if err := zw.Close(); err != nil {
+		return 0, err
+	}
+	return f.Seek(0, io.SeekCurrent)
+}
+
+// evict removes the expired blobs and then the least recently used blobs
+// other than keep until the cache is within its limit.
+func (c *Cache) evict(idx index, keep string) error {
+	if c.limit <= 0 {
+		return nil
+	}
+	now := time.Now()
+	var total int64
+	keys := make([]string, 0, len(idx))
+	for k, e := range idx {
+		total += e.Size
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := idx[keys[i]], idx[keys[j]]
+		if x, y := a.expired(now), b.expired(now); x != y {
+			return x
+		}
+		return a.Used.Before(b.Used)
+	})
+	for _, k := range keys {
+		if total <= c.limit && !idx[k].expired(now) {
+			break
+		}
+		if k == keep {
+			continue
+		}
+		if err := os.Remove(c.path(k)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		total -= idx[k].Size
+		delete(idx, k)
+	}
+	return nil
+}
+
+// update locks the index, reads it, calls f, and writes the index back,
+// even if f returns an error, since f may have modified it.
+func (c *Cache) update(f func(index) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	l, err := c.b.LockFile("cache", c.name+"/index.json")
+	if err == nil {
+		defer l.Unlock()
+	} else if !errors.Is(err, errors.ErrUnsupported) {
+		return err
+	}
+
+	idx := make(index)
+	data, err := os.ReadFile(filepath.Join(c.dir, "index.json"))
+	if err == nil {
+		// A corrupt index is discarded; the blobs are then unknown to the
+		// cache, but the cache keeps working.
+		json.Unmarshal(data, &idx)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	ferr := f(idx)
+	data, err = json.Marshal(idx)
+	if err == nil {
+		err = c.b.WriteCacheFile(c.name+"/index.json", data, 0644)
+	}
+	if ferr != nil {
+		return ferr
+	}
+	return err
+}
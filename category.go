@@ -0,0 +1,182 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"errors"
+	"os"
+	"sync"
+)
+
+// Category describes a type of files that follows the same rules as the
+// types defined by the specification: files are written relative to a single
+// user base directory, and searched for relative to that directory and then
+// to a set of preference ordered global base directories.
+//
+// Besides the categories registered with RegisterCategory, the following
+// built-in categories are available: "config", "data", "cache", "state",
+// "runtime", and "bin".
+type Category struct {
+	// HomeEnv is the environment variable that defines the user base
+	// directory. It must be set.
+	HomeEnv string
+
+	// Default is the user base directory that is used if HomeEnv is not set.
+	// It may contain "$HOME", which is replaced by the home directory.
+	Default string
+
+	// DirsEnv is the environment variable that defines the global base
+	// directories, which are separated by os.PathListSeparator. If DirsEnv
+	// and DirsDefault are empty, the category has no global directories.
+	DirsEnv string
+
+	// DirsDefault are the global base directories that are used if DirsEnv
+	// is not set, separated by os.PathListSeparator.
+	DirsDefault string
+}
+
+var (
+	// ErrUnknownCategory is returned when a category is used that is neither
+	// built-in nor registered.
+	ErrUnknownCategory = errors.New("unknown XDG category")
+
+	// ErrCategoryExists is returned by RegisterCategory if the name is
+	// already in use.
+	ErrCategoryExists = errors.New("XDG category already exists")
+
+	// ErrInvalidCategory is returned by RegisterCategory if the category
+	// does not define HomeEnv.
+	ErrInvalidCategory = errors.New("XDG category has no HomeEnv")
+)
+
+var (
+	categoriesMu sync.RWMutex
+	categories   = make(map[string]Category)
+)
+
+// builtin is true for the names of the built-in categories.
+var builtin = map[string]bool{
+	"config":  true,
+	"data":    true,
+	"cache":   true,
+	"state":   true,
+	"runtime": true,
+	"bin":     true,
+}
+
+// RegisterCategory registers the category c under name, so that it can be
+// used with the generic functions User, Find, FindAll, Merge, MergeR, Open,
+// and Paths.
+//
+// Categories are resolved together with the built-in categories, so only
+// a BaseDirs created after registration knows about c. If the package has
+// already been initialized, call Reload to make c available to the package
+// functions.
+func RegisterCategory(name string, c Category) error {
+	if c.HomeEnv == "" {
+		return ErrInvalidCategory
+	}
+
+	categoriesMu.Lock()
+	defer categoriesMu.Unlock()
+	if _, ok := categories[name]; ok || builtin[name] {
+		return ErrCategoryExists
+	}
+	categories[name] = c
+	return nil
+}
+
+// categoryDirs contains the directories of a resolved category.
+type categoryDirs struct {
+	env  string
+	home string
+	dirs []string
+}
+
+// customCategories resolves all registered categories with r.
+func (r *resolver) customCategories() map[string]categoryDirs {
+	categoriesMu.RLock()
+	defer categoriesMu.RUnlock()
+
+	m := make(map[string]categoryDirs, len(categories))
+	for name, c := range categories {
+		d := categoryDirs{env: c.HomeEnv}
+		d.home = r.path(c.HomeEnv, c.Default)
+		if c.DirsEnv != "" || c.DirsDefault != "" {
+			d.dirs = r.paths(c.DirsEnv, c.DirsDefault)
+		}
+		m[name] = d
+	}
+	return m
+}
+
+// category returns the directories of the category name.
+func (b *BaseDirs) category(name string) (categoryDirs, bool) {
+	switch name {
+	case "config":
+		return categoryDirs{"XDG_CONFIG_HOME", b.ConfigHome, b.ConfigDirs}, true
+	case "data":
+		return categoryDirs{"XDG_DATA_HOME", b.DataHome, b.DataDirs}, true
+	case "cache":
+		return categoryDirs{"XDG_CACHE_HOME", b.CacheHome, nil}, true
+	case "state":
+		return categoryDirs{"XDG_STATE_HOME", b.StateHome, nil}, true
+	case "runtime":
+		return categoryDirs{"XDG_RUNTIME_DIR", b.RuntimeDir, nil}, true
+	case "bin":
+		return categoryDirs{"XDG_BIN_HOME", b.BinHome, nil}, true
+	}
+	d, ok := b.custom[name]
+	return d, ok
+}
+
+// Paths returns the preference ordered base directories of category, in
+// the same way as ConfigPaths. If category is unknown, nil is returned.
+func (b *BaseDirs) Paths(category string) []string {
+	d, ok := b.category(category)
+	if !ok {
+		return nil
+	}
+	return combine(d.home, d.dirs)
+}
+
+// User returns the path of file in the user base directory of category.
+// If category is unknown, "" is returned.
+func (b *BaseDirs) User(category, file string) string {
+	d, _ := b.category(category)
+	return join(d.home, file)
+}
+
+func (b *BaseDirs) Find(category, file string) string { return find(file, b.Paths(category)) }
+func (b *BaseDirs) FindAll(category, file string) []string {
+	return findAll(file, b.Paths(category))
+}
+
+func (b *BaseDirs) Merge(category, file string, f MergeFunc) error {
+	if _, ok := b.category(category); !ok {
+		return ErrUnknownCategory
+	}
+	return merge(file, f, b.Paths(category))
+}
+func (b *BaseDirs) MergeR(category, file string, f MergeFunc) error {
+	if _, ok := b.category(category); !ok {
+		return ErrUnknownCategory
+	}
+	return mergeR(file, f, b.Paths(category))
+}
+
+func (b *BaseDirs) Open(category, file string, flag int) (*os.File, error) {
+	return b.OpenFile(category, file, flag, dirPerm(category)&^0111)
+}
+func (b *BaseDirs) OpenFile(category, file string, flag int, perm os.FileMode) (*os.File, error) {
+	if category == "runtime" {
+		return b.OpenRuntimeFile(file, flag, perm)
+	}
+	d, ok := b.category(category)
+	if !ok {
+		return nil, ErrUnknownCategory
+	}
+	return b.open(d.home, d.env, file, flag, dirPerm(category), perm)
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CleanOptions control how CleanCacheOpt removes files.
+type CleanOptions struct {
+	// DryRun makes CleanCacheOpt only report the files that it would
+	// remove, without removing them.
+	DryRun bool
+
+	// Progress, if not nil, is called with the path and size of each file
+	// that is removed, or would be removed if DryRun is set.
+	Progress func(path string, size int64)
+}
+
+// CleanCache removes the files in the directory prefix of CacheHome, e.g.
+// "dromi/thumbnails", that have been neither modified nor, where the system
+// records it, accessed within olderThan. Directories that become empty are
+// removed as well, except prefix itself. It returns the total size of the
+// files that were removed.
+//
+// If prefix does not exist, nothing is removed. Errors do not stop the
+// cleaning; they are joined and returned at the end.
+func (b *BaseDirs) CleanCache(prefix string, olderThan time.Duration) (removed int64, err error) {
+	return b.CleanCacheOpt(prefix, olderThan, CleanOptions{})
+}
+
+// CleanCacheOpt is like CleanCache, but removes files as opts specifies.
+func (b *BaseDirs) CleanCacheOpt(prefix string, olderThan time.Duration, opts CleanOptions) (removed int64, err error) {
+	if b.CacheHome == "" {
+		return 0, errUnresolved("XDG_CACHE_HOME")
+	}
+	root := join(b.CacheHome, prefix)
+	if root == "" {
+		return 0, errInvalidFile("clean", prefix)
+	}
+
+	var (
+		errs   []error
+		dirs   []string
+		cutoff = time.Now().Add(-olderThan)
+	)
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == root && errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipAll
+			}
+			errs = append(errs, err)
+			return nil
+		}
+		if d.IsDir() {
+			if p != root {
+				dirs = append(dirs, p)
+			}
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+		last := fi.ModTime()
+		if at, ok := fileAtime(fi); ok && at.After(last) {
+			last = at
+		}
+		if !last.Before(cutoff) {
+			return nil
+		}
+		if !opts.DryRun {
+			if err := os.Remove(p); err != nil {
+				errs = append(errs, err)
+				return nil
+			}
+		}
+		removed += fi.Size()
+		if opts.Progress != nil {
+			opts.Progress(p, fi.Size())
+		}
+		return nil
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if !opts.DryRun {
+		// Remove directories that are now empty, deepest first; removing a
+		// directory that is not empty fails, which is intended.
+		for i := len(dirs) - 1; i >= 0; i-- {
+			os.Remove(dirs[i])
+		}
+		InvalidateCache()
+	}
+	return removed, errors.Join(errs...)
+}
+
+func CleanCache(prefix string, olderThan time.Duration) (removed int64, err error) {
+	return defaults().CleanCache(prefix, olderThan)
+}
+func CleanCacheOpt(prefix string, olderThan time.Duration, opts CleanOptions) (removed int64, err error) {
+	return defaults().CleanCacheOpt(prefix, olderThan, opts)
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// registry contains the runtime files that CleanupRuntime removes, in the
+// order in which they were tracked, with the function that removes each.
+var registry struct {
+	sync.Mutex
+	order  []string
+	remove map[string]func() error
+}
+
+func track(p string, remove func() error) {
+	registry.Lock()
+	defer registry.Unlock()
+	if registry.remove == nil {
+		registry.remove = make(map[string]func() error)
+	}
+	if _, ok := registry.remove[p]; !ok {
+		registry.order = append(registry.order, p)
+	}
+	registry.remove[p] = remove
+}
+
+// TrackRuntimeFile adds the file p to the files that CleanupRuntime removes.
+// The sockets, named pipes, and PID files that the runtime helpers of this
+// package create are tracked automatically.
+func TrackRuntimeFile(p string) { track(p, func() error { return os.Remove(p) }) }
+
+// UntrackRuntimeFile removes the file p from the files that CleanupRuntime
+// removes, without removing the file itself.
+func UntrackRuntimeFile(p string) {
+	registry.Lock()
+	defer registry.Unlock()
+	if _, ok := registry.remove[p]; !ok {
+		return
+	}
+	delete(registry.remove, p)
+	for i, q := range registry.order {
+		if q == p {
+			registry.order = append(registry.order[:i], registry.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// CleanupRuntime removes all tracked runtime files, in the reverse order in
+// which they were tracked, and forgets them. It should be called when the
+// application shuts down gracefully; see also CleanupRuntimeOnSignal.
+// Files that no longer exist are ignored; other errors are joined.
+func CleanupRuntime() error {
+	registry.Lock()
+	order, remove := registry.order, registry.remove
+	registry.order, registry.remove = nil, nil
+	registry.Unlock()
+
+	var errs []error
+	for i := len(order) - 1; i >= 0; i-- {
+		if err := remove[order[i]](); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// CleanupRuntimeOnSignal calls CleanupRuntime when the process receives one
+// of sigs, or os.Interrupt or SIGTERM if none are given, and then lets the
+// signal take its default effect, which usually terminates the process.
+// Calling stop uninstalls the handler.
+func CleanupRuntimeOnSignal(sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	c := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(c, sigs...)
+	go func() {
+		select {
+		case sig := <-c:
+			CleanupRuntime()
+			signal.Reset(sigs...)
+			p, err := os.FindProcess(os.Getpid())
+			if err == nil {
+				err = p.Signal(sig)
+			}
+			if err != nil {
+				os.Exit(1)
+			}
+		case <-done:
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(c)
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"context"
+	"os"
+)
+
+// FindAllContext is like FindAll, but checks ctx before probing each base
+// directory, and returns ctx.Err() if ctx is done. A single probe cannot be
+// interrupted, but a slow network home directory no longer holds up the
+// whole search past a deadline.
+func (b *BaseDirs) FindAllContext(ctx context.Context, category, file string) ([]string, error) {
+	return findAllContext(ctx, file, b.Paths(category))
+}
+
+// MergeContext is like Merge, but checks ctx before probing each base
+// directory and before calling f for each file, and returns ctx.Err()
+// if ctx is done.
+func (b *BaseDirs) MergeContext(ctx context.Context, category, file string, f MergeFunc) error {
+	return b.mergeContext(ctx, category, file, f, false)
+}
+
+// MergeRContext is like MergeR, but honors ctx in the same way as MergeContext.
+func (b *BaseDirs) MergeRContext(ctx context.Context, category, file string, f MergeFunc) error {
+	return b.mergeContext(ctx, category, file, f, true)
+}
+
+func (b *BaseDirs) mergeContext(ctx context.Context, category, file string, f MergeFunc, r bool) error {
+	if _, ok := b.category(category); !ok {
+		return ErrUnknownCategory
+	}
+	ps, err := findAllContext(ctx, file, b.Paths(category))
+	if err != nil {
+		return err
+	}
+	if r {
+		ps = reverse(ps)
+	}
+	return mergeFiles(ps, func(p string) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return f(p)
+	})
+}
+
+func (b *BaseDirs) FindAllConfigContext(ctx context.Context, file string) ([]string, error) {
+	return b.FindAllContext(ctx, "config", file)
+}
+func (b *BaseDirs) FindAllDataContext(ctx context.Context, file string) ([]string, error) {
+	return b.FindAllContext(ctx, "data", file)
+}
+func (b *BaseDirs) MergeConfigContext(ctx context.Context, file string, f MergeFunc) error {
+	return b.MergeContext(ctx, "config", file, f)
+}
+func (b *BaseDirs) MergeDataContext(ctx context.Context, file string, f MergeFunc) error {
+	return b.MergeContext(ctx, "data", file, f)
+}
+
+// dirsKey is the key under which WithDirs stores a BaseDirs in a context.
+type dirsKey struct{}
+
+// WithDirs returns a copy of ctx that carries b, so that code acting on
+// behalf of different users, such as a multi-tenant daemon, can resolve
+// paths against the base directories of each request without global state.
+// The package-level *Context functions, such as FindAllContext, use the
+// BaseDirs from their context.
+func WithDirs(ctx context.Context, b *BaseDirs) context.Context {
+	return context.WithValue(ctx, dirsKey{}, b)
+}
+
+// FromContext returns the BaseDirs carried by ctx, or the default BaseDirs
+// of the package if ctx carries none.
+func FromContext(ctx context.Context) *BaseDirs {
+	if b, ok := ctx.Value(dirsKey{}).(*BaseDirs); ok && b != nil {
+		return b
+	}
+	return defaults()
+}
+
+func FindAllContext(ctx context.Context, category, file string) ([]string, error) {
+	return FromContext(ctx).FindAllContext(ctx, category, file)
+}
+func MergeContext(ctx context.Context, category, file string, f MergeFunc) error {
+	return FromContext(ctx).MergeContext(ctx, category, file, f)
+}
+func MergeRContext(ctx context.Context, category, file string, f MergeFunc) error {
+	return FromContext(ctx).MergeRContext(ctx, category, file, f)
+}
+func FindAllConfigContext(ctx context.Context, file string) ([]string, error) {
+	return FromContext(ctx).FindAllConfigContext(ctx, file)
+}
+func FindAllDataContext(ctx context.Context, file string) ([]string, error) {
+	return FromContext(ctx).FindAllDataContext(ctx, file)
+}
+func MergeConfigContext(ctx context.Context, file string, f MergeFunc) error {
+	return FromContext(ctx).MergeConfigContext(ctx, file, f)
+}
+func MergeDataContext(ctx context.Context, file string, f MergeFunc) error {
+	return FromContext(ctx).MergeDataContext(ctx, file, f)
+}
+
+func findAllContext(ctx context.Context, file string, paths []string) ([]string, error) {
+	ps := make([]string, 0, len(paths))
+	for _, dir := range paths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		p := join(dir, file)
+		if _, err := os.Stat(p); err != nil {
+			continue
+		}
+		ps = append(ps, p)
+	}
+	return ps, nil
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package desktop
+
+import (
+	"errors"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/goulash/xdg"
+)
+
+// AutostartDirs manages the applications that are started at login, as
+// defined by the Desktop Application Autostart specification: entries in
+// the directory autostart of ConfigHome and of each of ConfigDirs, e.g.
+// ~/.config/autostart/dromi.desktop.
+type AutostartDirs struct {
+	b *xdg.BaseDirs
+}
+
+// Autostart manages the autostart directories of the default base
+// directories of package xdg.
+var Autostart AutostartDirs
+
+// AutostartFor returns an AutostartDirs that uses the base directories of b.
+func AutostartFor(b *xdg.BaseDirs) AutostartDirs { return AutostartDirs{b} }
+
+func (a AutostartDirs) dirs() *xdg.BaseDirs {
+	if a.b == nil {
+		return xdg.Default()
+	}
+	return a.b
+}
+
+// List returns the entries that should be started at login in the current
+// desktop environment, as given by $XDG_CURRENT_DESKTOP in the environment
+// of the base directories; see xdg.BaseDirs.Getenv. If several autostart
+// directories contain an entry with the same name, only the one in the
+// most preferred directory is considered. Entries that are Hidden,
+// that are not shown in the current desktop according to OnlyShowIn and
+// NotShowIn, or whose TryExec program cannot be found, are left out.
+// Entries that cannot be read are skipped, and their errors are joined
+// into the returned error.
+func (a AutostartDirs) List() ([]*Entry, error) {
+	b := a.dirs()
+	current := strings.Split(b.Getenv("XDG_CURRENT_DESKTOP"), ":")
+	var (
+		es   []*Entry
+		errs []error
+		seen = make(map[string]bool)
+	)
+	for _, dir := range b.FindAllConfigDirs("autostart") {
+		ms, err := filepath.Glob(filepath.Join(dir, "*.desktop"))
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range ms {
+			id := filepath.Base(p)
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			e, err := ParseFile(p)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			e.ID = id
+			if e.Hidden() || !e.shownIn(current) || !e.installed() {
+				continue
+			}
+			es = append(es, e)
+		}
+	}
+	return es, errors.Join(errs...)
+}
+
+// shownIn returns true if e should be shown in one of the desktop
+// environments current.
+func (e *Entry) shownIn(current []string) bool {
+	if only, ok := e.File.Strings(Group, "OnlyShowIn"); ok {
+		for _, d := range only {
+			if d != "" && slices.Contains(current, d) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, d := range e.NotShowIn() {
+		if d != "" && slices.Contains(current, d) {
+			return false
+		}
+	}
+	return true
+}
+
+// installed returns false if the program of TryExec cannot be found.
+func (e *Entry) installed() bool {
+	p := e.TryExec()
+	if p == "" {
+		return true
+	}
+	_, err := exec.LookPath(p)
+	return err == nil
+}
+
+// Install writes e to the user autostart directory, so that it is started
+// at login. The file is named after the ID of e, or else after its
+// Filename; if e has neither, an error is returned.
+func (a AutostartDirs) Install(e *Entry) error {
+	name := e.ID
+	if name == "" && e.Filename != "" {
+		name = filepath.Base(e.Filename)
+	}
+	if name == "" {
+		return errors.New("desktop: entry has neither ID nor Filename")
+	}
+	var sb strings.Builder
+	if _, err := e.WriteTo(&sb); err != nil {
+		return err
+	}
+	return a.dirs().WriteConfigFile("autostart/"+name, []byte(sb.String()), 0644)
+}
+
+// Disable stops the entry name, such as "dromi.desktop", from being started
+// at login, by writing a copy of it with Hidden=true to the user autostart
+// directory, which overrides the entries of the system. If there is no
+// autostart entry name, the error wraps fs.ErrNotExist.
+func (a AutostartDirs) Disable(name string) error {
+	b := a.dirs()
+	file := "autostart/" + name
+	p := b.FindConfig(file)
+	if p == "" {
+		return &fs.PathError{Op: "disable", Path: name, Err: fs.ErrNotExist}
+	}
+	e, err := ParseFile(p)
+	if err != nil {
+		return err
+	}
+	e.File.SetBool(Group, "Hidden", true)
+	var sb strings.Builder
+	if _, err := e.WriteTo(&sb); err != nil {
+		return err
+	}
+	return b.WriteConfigFile(file, []byte(sb.String()), 0644)
+}
@@ -0,0 +1,264 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+// Package desktop reads and writes desktop entries, the .desktop files of
+// the freedesktop.org Desktop Entry specification, which describe how an
+// application is launched and how it appears in menus:
+//
+//	e, err := desktop.Find("org.example.Dromi.desktop")
+//	fmt.Println(e.Name(), e.Exec(), e.Categories())
+//
+// An Entry is a view of a keyfile.File. Keys that Entry has no accessor
+// for, such as those of other groups or keys beginning with X-, are kept
+// in the File, so that an entry that is written again is unchanged except
+// for the keys that were set.
+package desktop
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/goulash/xdg"
+	"github.com/goulash/xdg/keyfile"
+)
+
+// Group is the name of the main group of a desktop entry.
+const Group = "Desktop Entry"
+
+// The types of desktop entries.
+const (
+	Application = "Application"
+	Link        = "Link"
+	Directory   = "Directory"
+)
+
+// Entry is a desktop entry.
+type Entry struct {
+	// File contains the keys of the entry. It can be used to read and
+	// set keys that Entry has no accessors for.
+	File *keyfile.File
+
+	// ID is the desktop file ID of the entry, such as
+	// "org.example.Dromi.desktop", if it was found by Find or All.
+	ID string
+
+	// Filename is the file that the entry was read from, if any.
+	Filename string
+
+	// Locale is used to look up localized values, such as Name[de]. It has
+	// the form lang_COUNTRY.ENCODING@MODIFIER; Parse sets it from the
+	// environment, like the locale of a C program.
+	Locale string
+}
+
+// New returns an entry of type typ, which is one of Application, Link, and
+// Directory, with the given name.
+func New(typ, name string) *Entry {
+	e := &Entry{File: keyfile.New(), Locale: envLocale()}
+	e.File.SetString(Group, "Type", typ)
+	e.File.SetString(Group, "Name", name)
+	return e
+}
+
+// Parse reads a desktop entry from r. It is an error if r is not a key
+// file, or if it has no Desktop Entry group.
+func Parse(r io.Reader) (*Entry, error) {
+	f, err := keyfile.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	if !f.HasGroup(Group) {
+		return nil, fmt.Errorf("desktop: missing [%s] group", Group)
+	}
+	return &Entry{File: f, Locale: envLocale()}, nil
+}
+
+// ParseFile reads the desktop entry name.
+func ParseFile(name string) (*Entry, error) {
+	fd, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+	e, err := Parse(fd)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	e.Filename = name
+	return e, nil
+}
+
+// WriteTo writes e to w.
+func (e *Entry) WriteTo(w io.Writer) (int64, error) { return e.File.WriteTo(w) }
+
+// WriteFile writes e to the file name, creating it with perm if necessary.
+func (e *Entry) WriteFile(name string, perm os.FileMode) error {
+	return e.File.WriteFile(name, perm)
+}
+
+// envLocale returns the locale for messages from the environment.
+func envLocale() string {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if l := xdg.Getenv(env); l != "" {
+			return l
+		}
+	}
+	return ""
+}
+
+func (e *Entry) str(key string) string {
+	s, _ := e.File.String(Group, key)
+	return s
+}
+
+func (e *Entry) localeStr(key string) string {
+	s, _ := e.File.LocaleString(Group, key, e.Locale)
+	return s
+}
+
+func (e *Entry) strs(key string) []string {
+	xs, _ := e.File.Strings(Group, key)
+	return xs
+}
+
+func (e *Entry) localeStrs(key string) []string {
+	xs, _ := e.File.LocaleStrings(Group, key, e.Locale)
+	return xs
+}
+
+func (e *Entry) boolean(key string) bool {
+	b, _ := e.File.Bool(Group, key)
+	return b
+}
+
+// Type returns the type of the entry, e.g. Application.
+func (e *Entry) Type() string { return e.str("Type") }
+
+// Version returns the version of the specification that the entry
+// conforms to, e.g. "1.5".
+func (e *Entry) Version() string { return e.str("Version") }
+
+// Name returns the localized name of the application, e.g. "Mozilla".
+func (e *Entry) Name() string { return e.localeStr("Name") }
+
+// GenericName returns the localized generic name of the application, e.g.
+// "Web Browser".
+func (e *Entry) GenericName() string { return e.localeStr("GenericName") }
+
+// Comment returns the localized tooltip of the entry.
+func (e *Entry) Comment() string { return e.localeStr("Comment") }
+
+// Icon returns the icon of the entry, which is either an absolute path or
+// a name that is looked up in the icon theme.
+func (e *Entry) Icon() string { return e.localeStr("Icon") }
+
+// Exec returns the command line of the application, including field codes
+// such as %f.
+func (e *Entry) Exec() string { return e.str("Exec") }
+
+// TryExec returns the executable that must exist for the entry to be shown.
+func (e *Entry) TryExec() string { return e.str("TryExec") }
+
+// Path returns the working directory in which the application is run.
+func (e *Entry) Path() string { return e.str("Path") }
+
+// URL returns the URL of an entry of type Link.
+func (e *Entry) URL() string { return e.str("URL") }
+
+// StartupWMClass returns the WM class that the application is known to map
+// its windows with.
+func (e *Entry) StartupWMClass() string { return e.str("StartupWMClass") }
+
+// Terminal returns true if the application runs in a terminal.
+func (e *Entry) Terminal() bool { return e.boolean("Terminal") }
+
+// NoDisplay returns true if the entry should not be shown in menus.
+func (e *Entry) NoDisplay() bool { return e.boolean("NoDisplay") }
+
+// Hidden returns true if the entry has been deleted, which is how a user
+// hides an entry of the system.
+func (e *Entry) Hidden() bool { return e.boolean("Hidden") }
+
+// StartupNotify returns true if the application supports startup
+// notification.
+func (e *Entry) StartupNotify() bool { return e.boolean("StartupNotify") }
+
+// Categories returns the categories in which the entry should be shown in
+// a menu, e.g. [AudioVideo Player].
+func (e *Entry) Categories() []string { return e.strs("Categories") }
+
+// MimeTypes returns the MIME types that the application supports.
+func (e *Entry) MimeTypes() []string { return e.strs("MimeType") }
+
+// Keywords returns the localized keywords by which the entry can be found.
+func (e *Entry) Keywords() []string { return e.localeStrs("Keywords") }
+
+// OnlyShowIn returns the desktop environments in which the entry should be
+// shown, or nil if there is no restriction.
+func (e *Entry) OnlyShowIn() []string { return e.strs("OnlyShowIn") }
+
+// NotShowIn returns the desktop environments in which the entry should not
+// be shown.
+func (e *Entry) NotShowIn() []string { return e.strs("NotShowIn") }
+
+// SetName sets the unlocalized name of the entry.
+func (e *Entry) SetName(name string) { e.File.SetString(Group, "Name", name) }
+
+// SetExec sets the command line of the application.
+func (e *Entry) SetExec(exec string) { e.File.SetString(Group, "Exec", exec) }
+
+// SetIcon sets the icon of the entry.
+func (e *Entry) SetIcon(icon string) { e.File.SetString(Group, "Icon", icon) }
+
+// SetCategories sets the categories of the entry.
+func (e *Entry) SetCategories(xs []string) { e.File.SetStrings(Group, "Categories", xs) }
+
+// SetMimeTypes sets the MIME types that the application supports.
+func (e *Entry) SetMimeTypes(xs []string) { e.File.SetStrings(Group, "MimeType", xs) }
+
+// Action is an additional action of an application, such as opening a new
+// window, which is described in a group [Desktop Action ID].
+type Action struct {
+	ID string
+	e  *Entry
+}
+
+// Actions returns the actions of the entry that are listed in its Actions
+// key and have a group.
+func (e *Entry) Actions() []Action {
+	var as []Action
+	for _, id := range e.strs("Actions") {
+		if id != "" && e.File.HasGroup(actionGroup(id)) {
+			as = append(as, Action{id, e})
+		}
+	}
+	return as
+}
+
+func actionGroup(id string) string { return "Desktop Action " + id }
+
+// Name returns the localized name of the action.
+func (a Action) Name() string {
+	s, _ := a.e.File.LocaleString(actionGroup(a.ID), "Name", a.e.Locale)
+	return s
+}
+
+// Icon returns the icon of the action.
+func (a Action) Icon() string {
+	s, _ := a.e.File.LocaleString(actionGroup(a.ID), "Icon", a.e.Locale)
+	return s
+}
+
+// Exec returns the command line of the action.
+func (a Action) Exec() string {
+	s, _ := a.e.File.String(actionGroup(a.ID), "Exec")
+	return s
+}
+
+// idFor returns the desktop file ID of the file rel, which is relative to
+// an applications directory: subdirectories become prefixes, so that
+// kde/konsole.desktop has the ID kde-konsole.desktop.
+func idFor(rel string) string { return strings.ReplaceAll(rel, "/", "-") }
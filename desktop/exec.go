@@ -0,0 +1,243 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package desktop
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/goulash/xdg"
+)
+
+var (
+	// ErrNotInstalled is returned by Command if the program of TryExec
+	// cannot be found, which means that the application is not installed.
+	ErrNotInstalled = errors.New("desktop: application is not installed")
+
+	// ErrTooManyFiles is returned by Command if several files are given,
+	// but Exec accepts only one, with %f or %u. Call Command once for each
+	// file instead, as the specification demands.
+	ErrTooManyFiles = errors.New("desktop: application accepts only one file")
+)
+
+// TerminalCommand is the command that runs the command line of an
+// application with Terminal=true, to which the command line is appended,
+// e.g. []string{"xterm", "-e"}. If it is nil, which is the default, the
+// terminal is $TERMINAL, xdg-terminal-exec, x-terminal-emulator, or xterm,
+// whichever is found first.
+var TerminalCommand []string
+
+// Command returns the command that launches the application of e with
+// files, which are local paths or URLs. The field codes in Exec are
+// expanded as the specification defines: %f and %u are replaced by one
+// file, %F and %U by all of them, %i by the icon, %c by the name, and %k by
+// Filename; deprecated field codes are removed. Paths are given to %u and
+// %U as file URLs, and file URLs to %f and %F as paths. If Exec accepts only
+// one file, with %f or %u, but several are given, the error is
+// ErrTooManyFiles; the files are never silently dropped.
+//
+// If Terminal is true, the command runs in a terminal; see TerminalCommand.
+// The command is run in Path, if it is set. If TryExec is set and its
+// program cannot be found, the error is ErrNotInstalled.
+func (e *Entry) Command(files ...string) (*exec.Cmd, error) {
+	if p := e.TryExec(); p != "" {
+		if _, err := exec.LookPath(p); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrNotInstalled, err)
+		}
+	}
+	return e.command(e.Exec(), files)
+}
+
+// Command returns the command that runs the action with files, in the same
+// way as Entry.Command.
+func (a Action) Command(files ...string) (*exec.Cmd, error) {
+	return a.e.command(a.Exec(), files)
+}
+
+func (e *Entry) command(line string, files []string) (*exec.Cmd, error) {
+	if line == "" {
+		return nil, errors.New("desktop: entry has no Exec key")
+	}
+	args, err := splitExec(line)
+	if err != nil {
+		return nil, err
+	}
+	args, err = e.expand(args, files)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return nil, errors.New("desktop: Exec has no program")
+	}
+	if e.Terminal() {
+		t, err := terminal()
+		if err != nil {
+			return nil, err
+		}
+		args = append(t, args...)
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	if cmd.Err != nil {
+		return nil, cmd.Err
+	}
+	cmd.Dir = e.Path()
+	return cmd, nil
+}
+
+// splitExec splits the command line s into arguments. Arguments may be
+// quoted with double quotes, inside of which a backslash escapes ", `, $,
+// and \.
+func splitExec(s string) ([]string, error) {
+	var (
+		args   []string
+		cur    strings.Builder
+		inArg  bool
+		quoted bool
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quoted && c == '\\' && i+1 < len(s) && strings.IndexByte("\"`$\\", s[i+1]) >= 0:
+			i++
+			cur.WriteByte(s[i])
+		case c == '"':
+			quoted = !quoted
+			inArg = true
+		case !quoted && (c == ' ' || c == '\t'):
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteByte(c)
+			inArg = true
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("desktop: unterminated quote in Exec %q", s)
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
+
+// expand replaces the field codes in args.
+func (e *Entry) expand(args, files []string) ([]string, error) {
+	var out []string
+	for _, a := range args {
+		// Field codes that make up a whole argument can expand to any
+		// number of arguments.
+		switch a {
+		case "%F":
+			out = append(out, mapFiles(files, toPath)...)
+			continue
+		case "%U":
+			out = append(out, mapFiles(files, toURL)...)
+			continue
+		case "%i":
+			if icon := e.Icon(); icon != "" {
+				out = append(out, "--icon", icon)
+			}
+			continue
+		case "%f", "%u", "%k":
+			if a == "%k" && e.Filename == "" || a != "%k" && len(files) == 0 {
+				continue
+			}
+		case "%d", "%D", "%n", "%N", "%v", "%m":
+			// Deprecated field codes are removed with their argument.
+			continue
+		}
+
+		var sb strings.Builder
+		for i := 0; i < len(a); i++ {
+			if a[i] != '%' || i+1 == len(a) {
+				sb.WriteByte(a[i])
+				continue
+			}
+			i++
+			switch a[i] {
+			case '%':
+				sb.WriteByte('%')
+			case 'f', 'u':
+				if len(files) > 1 {
+					return nil, ErrTooManyFiles
+				}
+				if len(files) == 1 {
+					if a[i] == 'f' {
+						sb.WriteString(toPath(files[0]))
+					} else {
+						sb.WriteString(toURL(files[0]))
+					}
+				}
+			case 'F', 'U':
+				return nil, fmt.Errorf("desktop: field code %%%c must be a whole argument", a[i])
+			case 'c':
+				sb.WriteString(e.Name())
+			case 'k':
+				sb.WriteString(e.Filename)
+			case 'i', 'd', 'D', 'n', 'N', 'v', 'm':
+				// %i is only valid as a whole argument, and the others
+				// are deprecated.
+			default:
+				return nil, fmt.Errorf("desktop: invalid field code %%%c", a[i])
+			}
+		}
+		out = append(out, sb.String())
+	}
+	return out, nil
+}
+
+func mapFiles(files []string, f func(string) string) []string {
+	xs := make([]string, len(files))
+	for i, x := range files {
+		xs[i] = f(x)
+	}
+	return xs
+}
+
+// toPath returns the local path of the file URL s, or s if it is not one.
+func toPath(s string) string {
+	if u, err := url.Parse(s); err == nil && u.Scheme == "file" && u.Path != "" {
+		return filepath.FromSlash(u.Path)
+	}
+	return s
+}
+
+// toURL returns s as a file URL if it is a path, or s if it is a URL.
+func toURL(s string) string {
+	if u, err := url.Parse(s); err == nil && len(u.Scheme) > 1 {
+		return s
+	}
+	if p, err := filepath.Abs(s); err == nil {
+		s = p
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(s)}).String()
+}
+
+// terminal returns the command that runs a command line in a terminal.
+func terminal() ([]string, error) {
+	if TerminalCommand != nil {
+		return append([]string(nil), TerminalCommand...), nil
+	}
+	if t := xdg.Getenv("TERMINAL"); t != "" {
+		return []string{t, "-e"}, nil
+	}
+	for _, t := range [][]string{
+		{"xdg-terminal-exec"},
+		{"x-terminal-emulator", "-e"},
+		{"xterm", "-e"},
+	} {
+		if _, err := exec.LookPath(t[0]); err == nil {
+			return t, nil
+		}
+	}
+	return nil, errors.New("desktop: no terminal found; set TerminalCommand")
+}
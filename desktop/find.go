@@ -0,0 +1,144 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package desktop
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goulash/xdg"
+)
+
+// Find returns the entry with the desktop file ID id, such as
+// "org.example.Dromi.desktop", from the applications directory of the first
+// XDG data directory that has it, e.g. ~/.local/share/applications. If there
+// is no such entry, the error wraps fs.ErrNotExist.
+func Find(id string) (*Entry, error) { return FindDirs(nil, id) }
+
+// FindDirs is like Find, but searches the data directories of b. If b is
+// nil, the default base directories of package xdg are used.
+func FindDirs(b *xdg.BaseDirs, id string) (*Entry, error) {
+	if !strings.ContainsAny(id, "/"+string(filepath.Separator)) {
+		for _, dir := range applicationDirs(b) {
+			if p := lookup(dir, id); p != "" {
+				e, err := ParseFile(p)
+				if err != nil {
+					return nil, err
+				}
+				e.ID = id
+				return e, nil
+			}
+		}
+	}
+	return nil, &fs.PathError{Op: "find", Path: id, Err: fs.ErrNotExist}
+}
+
+// lookup returns the path of the desktop file with the ID id in the
+// applications directory dir, or "" if there is none. Since each "-" in id
+// may stand for a subdirectory, see idFor, only the prefixes of id that are
+// subdirectories of dir are followed, so that dir is not walked.
+func lookup(dir, id string) string {
+	p := filepath.Join(dir, id)
+	if fi, err := os.Stat(p); err == nil && !fi.IsDir() {
+		return p
+	}
+	for i := 1; i < len(id); i++ {
+		if id[i] != '-' || id[:i] == "." || id[:i] == ".." {
+			continue
+		}
+		// Symbolic links to directories are not followed, as by walk.
+		sub := filepath.Join(dir, id[:i])
+		if fi, err := os.Lstat(sub); err == nil && fi.IsDir() {
+			if p := lookup(sub, id[i+1:]); p != "" {
+				return p
+			}
+		}
+	}
+	return ""
+}
+
+// All returns the entries in the applications directories of all XDG data
+// directories. If several directories contain an entry with the same ID,
+// only the one in the most preferred directory is returned, even if it is
+// Hidden, since that is how users hide the entries of the system. Entries
+// that cannot be read are skipped, and their errors are joined into the
+// returned error.
+func All() ([]*Entry, error) { return AllDirs(nil) }
+
+// AllDirs is like All, but searches the data directories of b. If b is nil,
+// the default base directories of package xdg are used.
+func AllDirs(b *xdg.BaseDirs) ([]*Entry, error) {
+	var (
+		es   []*Entry
+		errs []error
+		seen = make(map[string]bool)
+	)
+	err := walk(b, func(p, id string) (bool, error) {
+		if seen[id] {
+			return false, nil
+		}
+		seen[id] = true
+		e, err := ParseFile(p)
+		if err != nil {
+			errs = append(errs, err)
+			return false, nil
+		}
+		e.ID = id
+		es = append(es, e)
+		return false, nil
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return es, errors.Join(errs...)
+}
+
+// applicationDirs returns the applications directories of b that exist, in
+// order of preference.
+func applicationDirs(b *xdg.BaseDirs) []string {
+	if b == nil {
+		return xdg.FindAllDataDirs("applications")
+	}
+	return b.FindAllDataDirs("applications")
+}
+
+// walk calls f with the path and the ID of each .desktop file in the
+// applications directories of b, in order of preference, until f returns
+// true or an error.
+func walk(b *xdg.BaseDirs, f func(p, id string) (bool, error)) error {
+	stop := errors.New("stop")
+	for _, dir := range applicationDirs(b) {
+		err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				// Unreadable subdirectories are skipped.
+				return nil
+			}
+			if d.IsDir() || !strings.HasSuffix(p, ".desktop") {
+				return nil
+			}
+			rel, err := filepath.Rel(dir, p)
+			if err != nil {
+				return nil
+			}
+			done, err := f(p, idFor(filepath.ToSlash(rel)))
+			if err != nil {
+				return err
+			}
+			if done {
+				return stop
+			}
+			return nil
+		})
+		if err == stop {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,371 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package desktop
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Severity is the severity of a Problem.
+type Severity int
+
+const (
+	// Error means that the entry violates the specification.
+	Error Severity = iota
+	// Warning means that the entry uses deprecated features, or features
+	// that are likely to be mistakes.
+	Warning
+	// Hint means that the entry could be improved.
+	Hint
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Error:
+		return "error"
+	case Warning:
+		return "warning"
+	case Hint:
+		return "hint"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// Problem is a problem that Validate found in an entry.
+type Problem struct {
+	Severity Severity
+	Group    string // group of the problem, e.g. "Desktop Entry"
+	Key      string // key of the problem, or "" if it concerns the group
+	Msg      string // description of the problem
+}
+
+func (p Problem) String() string {
+	if p.Key == "" {
+		return fmt.Sprintf("%s: [%s]: %s", p.Severity, p.Group, p.Msg)
+	}
+	return fmt.Sprintf("%s: [%s] %s: %s", p.Severity, p.Group, p.Key, p.Msg)
+}
+
+// Validate checks e against the Desktop Entry specification, in the manner
+// of desktop-file-validate, and returns the problems that it finds, in the
+// order of the file. It checks that the keys that the type of the entry
+// requires are present, that keys and values are valid, including the
+// field codes in Exec, the Categories, and the icon names, and it reports
+// deprecated keys. An entry without problems returns nil.
+func Validate(e *Entry) []Problem {
+	v := &validator{e: e}
+	v.entry()
+	for _, a := range e.strs("Actions") {
+		if a == "" {
+			continue
+		}
+		if !e.File.HasGroup(actionGroup(a)) {
+			v.add(Error, Group, "Actions", fmt.Sprintf("action %q has no group [%s]", a, actionGroup(a)))
+			continue
+		}
+		v.action(a)
+	}
+	for _, g := range e.File.Groups() {
+		if id, ok := strings.CutPrefix(g, "Desktop Action "); ok && !slices.Contains(e.strs("Actions"), id) {
+			v.add(Warning, g, "", "action is not listed in Actions")
+		} else if g != Group && !ok && !strings.HasPrefix(g, "X-") {
+			v.add(Error, g, "", "groups extending the format must begin with X-")
+		}
+	}
+	return v.ps
+}
+
+type validator struct {
+	e  *Entry
+	ps []Problem
+}
+
+func (v *validator) add(s Severity, group, key, msg string) {
+	v.ps = append(v.ps, Problem{s, group, key, msg})
+}
+
+// keys are the keys of the Desktop Entry group, with whether they can be
+// localized.
+var keys = map[string]bool{
+	"Type": false, "Version": false, "Name": true, "GenericName": true,
+	"NoDisplay": false, "Comment": true, "Icon": true, "Hidden": false,
+	"OnlyShowIn": false, "NotShowIn": false, "DBusActivatable": false,
+	"TryExec": false, "Exec": false, "Path": false, "Terminal": false,
+	"Actions": false, "MimeType": false, "Categories": false,
+	"Implements": false, "Keywords": true, "StartupNotify": false,
+	"StartupWMClass": false, "URL": false, "PrefersNonDefaultGPU": false,
+	"SingleMainWindow": false,
+}
+
+// deprecatedKeys are keys that earlier versions of the specification
+// defined.
+var deprecatedKeys = map[string]bool{
+	"Encoding": true, "MiniIcon": true, "TerminalOptions": true,
+	"Protocols": true, "Extensions": true, "BinaryPattern": true,
+	"MapNotify": true, "SwallowTitle": true, "SwallowExec": true,
+	"SortOrder": true, "FilePattern": true,
+}
+
+var boolKeys = []string{
+	"NoDisplay", "Hidden", "DBusActivatable", "Terminal", "StartupNotify",
+	"PrefersNonDefaultGPU", "SingleMainWindow",
+}
+
+func (v *validator) entry() {
+	e := v.e
+	for _, k := range e.File.Keys(Group) {
+		base, locale, localized := strings.Cut(k, "[")
+		switch {
+		case strings.HasPrefix(base, "X-"):
+		case deprecatedKeys[base]:
+			v.add(Warning, Group, k, "key is deprecated")
+		case !has(keys, base):
+			v.add(Error, Group, k, "keys extending the format must begin with X-")
+		case localized && !keys[base]:
+			v.add(Error, Group, k, "key cannot be localized")
+		case localized && (!strings.HasSuffix(locale, "]") || locale == "]"):
+			v.add(Error, Group, k, "invalid locale")
+		}
+	}
+
+	typ, ok := e.File.String(Group, "Type")
+	switch {
+	case !ok:
+		v.add(Error, Group, "Type", "required key is missing")
+	case typ != Application && typ != Link && typ != Directory:
+		v.add(Error, Group, "Type", fmt.Sprintf("unknown type %q", typ))
+	}
+	if _, ok := e.File.Value(Group, "Name"); !ok {
+		v.add(Error, Group, "Name", "required key is missing")
+	}
+	if ver, ok := e.File.String(Group, "Version"); ok && !slices.Contains(versions, ver) {
+		v.add(Error, Group, "Version", fmt.Sprintf("unknown version %q", ver))
+	}
+	for _, k := range boolKeys {
+		if s, ok := e.File.Value(Group, k); ok && s != "true" && s != "false" {
+			v.add(Error, Group, k, fmt.Sprintf("value %q is not a boolean", s))
+		}
+	}
+
+	switch typ {
+	case Application:
+		_, hasExec := e.File.Value(Group, "Exec")
+		if dbus, _ := e.File.Bool(Group, "DBusActivatable"); !hasExec && !dbus {
+			v.add(Error, Group, "Exec", "required key is missing")
+		}
+	case Link:
+		if _, ok := e.File.Value(Group, "URL"); !ok {
+			v.add(Error, Group, "URL", "required key is missing")
+		}
+	}
+	if typ != Application {
+		for _, k := range []string{"Exec", "TryExec", "Path", "Terminal", "MimeType", "Categories", "Actions"} {
+			if _, ok := e.File.Value(Group, k); ok {
+				v.add(Warning, Group, k, fmt.Sprintf("key is only used by entries of type %s", Application))
+			}
+		}
+	}
+
+	if s, ok := e.File.String(Group, "Exec"); ok {
+		v.exec(Group, s)
+	}
+	for _, k := range e.File.Keys(Group) {
+		if base, _, _ := strings.Cut(k, "["); base == "Icon" {
+			s, _ := e.File.String(Group, k)
+			v.icon(Group, k, s)
+		}
+	}
+	if typ == Application {
+		v.categories()
+	}
+	v.environments()
+}
+
+func (v *validator) action(id string) {
+	g := actionGroup(id)
+	if _, ok := v.e.File.Value(g, "Name"); !ok {
+		v.add(Error, g, "Name", "required key is missing")
+	}
+	if s, ok := v.e.File.String(g, "Exec"); ok {
+		v.exec(g, s)
+	} else if dbus, _ := v.e.File.Bool(Group, "DBusActivatable"); !dbus {
+		v.add(Error, g, "Exec", "required key is missing")
+	}
+	if s, ok := v.e.File.String(g, "Icon"); ok {
+		v.icon(g, "Icon", s)
+	}
+}
+
+// exec checks the field codes and the quoting of the command line s.
+func (v *validator) exec(group, s string) {
+	if strings.TrimSpace(s) == "" {
+		v.add(Error, group, "Exec", "value is empty")
+		return
+	}
+	var (
+		quoted bool
+		files  int
+	)
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"':
+			quoted = !quoted
+		case c == '\\' && quoted:
+			i++
+		case c == '%':
+			if i++; i == len(s) {
+				v.add(Error, group, "Exec", "incomplete field code at the end")
+				return
+			}
+			code := s[i]
+			switch {
+			case code == '%':
+			case strings.IndexByte("fFuU", code) >= 0:
+				files++
+				if quoted {
+					v.add(Error, group, "Exec", fmt.Sprintf("field code %%%c is used inside a quoted argument", code))
+				}
+			case strings.IndexByte("ick", code) >= 0:
+				if quoted {
+					v.add(Error, group, "Exec", fmt.Sprintf("field code %%%c is used inside a quoted argument", code))
+				}
+			case strings.IndexByte("dDnNvm", code) >= 0:
+				v.add(Warning, group, "Exec", fmt.Sprintf("field code %%%c is deprecated", code))
+			default:
+				v.add(Error, group, "Exec", fmt.Sprintf("invalid field code %%%c", code))
+			}
+		}
+	}
+	if quoted {
+		v.add(Error, group, "Exec", "unterminated quoted argument")
+	}
+	if files > 1 {
+		v.add(Error, group, "Exec", "more than one of the field codes %f, %F, %u, and %U")
+	}
+}
+
+// icon checks that s is an absolute path or an icon name.
+func (v *validator) icon(group, key, s string) {
+	switch {
+	case s == "":
+		v.add(Error, group, key, "value is empty")
+	case filepath.IsAbs(s):
+	case strings.Contains(s, "/"):
+		v.add(Error, group, key, "icon is neither an absolute path nor an icon name")
+	default:
+		switch strings.ToLower(filepath.Ext(s)) {
+		case ".png", ".xpm", ".svg", ".svgz":
+			v.add(Warning, group, key, "icon name should not have an extension")
+		}
+	}
+}
+
+func (v *validator) categories() {
+	cs, ok := v.e.File.Strings(Group, "Categories")
+	if !ok {
+		v.add(Hint, Group, "Categories", "entry has no categories, so it cannot be placed in menus")
+		return
+	}
+	main := false
+	for _, c := range cs {
+		switch {
+		case c == "" || strings.HasPrefix(c, "X-"):
+		case mainCategories[c]:
+			main = true
+		case reservedCategories[c]:
+			if _, ok := v.e.File.Value(Group, "OnlyShowIn"); !ok {
+				v.add(Error, Group, "Categories", fmt.Sprintf("reserved category %q requires OnlyShowIn", c))
+			}
+		case !additionalCategories[c]:
+			v.add(Error, Group, "Categories", fmt.Sprintf("unknown category %q", c))
+		}
+	}
+	for _, c := range []string{"Audio", "Video"} {
+		if slices.Contains(cs, c) && !slices.Contains(cs, "AudioVideo") {
+			v.add(Error, Group, "Categories", fmt.Sprintf("category %q requires AudioVideo", c))
+		}
+	}
+	if !main {
+		v.add(Hint, Group, "Categories", "entry has no main category")
+	}
+}
+
+// environments checks the values of OnlyShowIn and NotShowIn.
+func (v *validator) environments() {
+	only, _ := v.e.File.Strings(Group, "OnlyShowIn")
+	not, _ := v.e.File.Strings(Group, "NotShowIn")
+	for _, k := range []string{"OnlyShowIn", "NotShowIn"} {
+		xs, _ := v.e.File.Strings(Group, k)
+		for _, x := range xs {
+			if x != "" && !environments[x] && !strings.HasPrefix(x, "X-") {
+				v.add(Error, Group, k, fmt.Sprintf("unknown desktop environment %q", x))
+			}
+		}
+	}
+	for _, x := range only {
+		if x != "" && slices.Contains(not, x) {
+			v.add(Error, Group, "NotShowIn", fmt.Sprintf("%q is also in OnlyShowIn", x))
+		}
+	}
+}
+
+var versions = []string{"1.0", "1.1", "1.2", "1.3", "1.4", "1.5"}
+
+var mainCategories = set(
+	"AudioVideo", "Audio", "Video", "Development", "Education", "Game",
+	"Graphics", "Network", "Office", "Science", "Settings", "System", "Utility",
+)
+
+var reservedCategories = set("Screensaver", "TrayIcon", "Applet", "Shell")
+
+var additionalCategories = set(
+	"Building", "Debugger", "IDE", "GUIDesigner", "Profiling",
+	"RevisionControl", "Translation", "Calendar", "ContactManagement",
+	"Database", "Dictionary", "Chart", "Email", "Finance", "FlowChart", "PDA",
+	"ProjectManagement", "Presentation", "Spreadsheet", "WordProcessor",
+	"2DGraphics", "VectorGraphics", "RasterGraphics", "3DGraphics",
+	"Scanning", "OCR", "Photography", "Publishing", "Viewer", "TextTools",
+	"DesktopSettings", "HardwareSettings", "Printing", "PackageManager",
+	"Dialup", "InstantMessaging", "Chat", "IRCClient", "Feed",
+	"FileTransfer", "HamRadio", "News", "P2P", "RemoteAccess", "Telephony",
+	"TelephonyTools", "VideoConference", "WebBrowser", "WebDevelopment",
+	"Midi", "Mixer", "Sequencer", "Tuner", "TV", "AudioVideoEditing",
+	"Player", "Recorder", "DiscBurning", "ActionGame", "AdventureGame",
+	"ArcadeGame", "BoardGame", "BlocksGame", "CardGame", "KidsGame",
+	"LogicGame", "RolePlaying", "Shooter", "Simulation", "SportsGame",
+	"StrategyGame", "Art", "Construction", "Music", "Languages",
+	"ArtificialIntelligence", "Astronomy", "Biology", "Chemistry",
+	"ComputerScience", "DataVisualization", "Economy", "Electricity",
+	"Geography", "Geology", "Geoscience", "History", "Humanities",
+	"ImageProcessing", "Literature", "Maps", "Math", "NumericalAnalysis",
+	"MedicalSoftware", "Physics", "Robotics", "Spirituality", "Sports",
+	"ParallelComputing", "Amusement", "Archiving", "Compression",
+	"Electronics", "Emulator", "Engineering", "FileTools", "FileManager",
+	"TerminalEmulator", "Filesystem", "Monitor", "Security", "Accessibility",
+	"Calculator", "Clock", "TextEditor", "Documentation", "Adult", "Core",
+	"KDE", "GNOME", "XFCE", "DDE", "GTK", "Qt", "Motif", "Java", "ConsoleOnly",
+)
+
+var environments = set(
+	"GNOME", "GNOME-Classic", "GNOME-Flashback", "KDE", "LXDE", "LXQt",
+	"MATE", "Razor", "ROX", "TDE", "Unity", "XFCE", "EDE", "Cinnamon",
+	"Pantheon", "Budgie", "Enlightenment", "DDE", "Endless", "Old",
+)
+
+func set(xs ...string) map[string]bool {
+	m := make(map[string]bool, len(xs))
+	for _, x := range xs {
+		m[x] = true
+	}
+	return m
+}
+
+func has(m map[string]bool, k string) bool {
+	_, ok := m[k]
+	return ok
+}
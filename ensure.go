@@ -0,0 +1,64 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import "os"
+
+// Ensure creates the directory dir relative to the user base directory of
+// category, if it does not exist yet, and returns its absolute path.
+// Directories are created with the permission 0755, except for runtime
+// directories, which are created with 0700, as the specification requires.
+func (b *BaseDirs) Ensure(category, dir string) (string, error) {
+	d, ok := b.category(category)
+	if !ok {
+		return "", ErrUnknownCategory
+	}
+	if d.home == "" {
+		return "", errUnresolved(d.env)
+	}
+	p := join(d.home, dir)
+	if p == "" {
+		return "", errInvalidFile("mkdir", dir)
+	}
+
+	perm := dirPerm(category)
+	if err := b.mkdirAll(p, perm); err != nil {
+		return "", err
+	}
+	if category == "runtime" {
+		// MkdirAll is subject to the umask, which must not be able to
+		// weaken the permissions, and p may have existed already.
+		if err := os.Chmod(p, perm); err != nil {
+			return "", err
+		}
+	}
+	return p, nil
+}
+
+func (b *BaseDirs) EnsureConfigDir(dir string) (string, error)  { return b.Ensure("config", dir) }
+func (b *BaseDirs) EnsureDataDir(dir string) (string, error)    { return b.Ensure("data", dir) }
+func (b *BaseDirs) EnsureCacheDir(dir string) (string, error)   { return b.Ensure("cache", dir) }
+func (b *BaseDirs) EnsureStateDir(dir string) (string, error)   { return b.Ensure("state", dir) }
+func (b *BaseDirs) EnsureRuntimeDir(dir string) (string, error) { return b.Ensure("runtime", dir) }
+
+// Ensure creates the directory dir relative to the application directory
+// of category, as BaseDirs.Ensure does. If dir is "", the application
+// directory itself is created.
+func (a *AppDirs) Ensure(category, dir string) (string, error) {
+	return a.dirs().Ensure(category, a.file(dir))
+}
+
+func (a *AppDirs) EnsureConfigDir(dir string) (string, error)  { return a.Ensure("config", dir) }
+func (a *AppDirs) EnsureDataDir(dir string) (string, error)    { return a.Ensure("data", dir) }
+func (a *AppDirs) EnsureCacheDir(dir string) (string, error)   { return a.Ensure("cache", dir) }
+func (a *AppDirs) EnsureStateDir(dir string) (string, error)   { return a.Ensure("state", dir) }
+func (a *AppDirs) EnsureRuntimeDir(dir string) (string, error) { return a.Ensure("runtime", dir) }
+
+func Ensure(category, dir string) (string, error) { return defaults().Ensure(category, dir) }
+func EnsureConfigDir(dir string) (string, error)  { return defaults().EnsureConfigDir(dir) }
+func EnsureDataDir(dir string) (string, error)    { return defaults().EnsureDataDir(dir) }
+func EnsureCacheDir(dir string) (string, error)   { return defaults().EnsureCacheDir(dir) }
+func EnsureStateDir(dir string) (string, error)   { return defaults().EnsureStateDir(dir) }
+func EnsureRuntimeDir(dir string) (string, error) { return defaults().EnsureRuntimeDir(dir) }
@@ -0,0 +1,63 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"os"
+	"strings"
+)
+
+// ExportEnv returns the resolved base directories, including defaults, as
+// environment variables in the "key=value" format of os.Environ, e.g.
+// "XDG_CONFIG_HOME=/home/ben/.config". Directories that could not be
+// resolved are left out. The result can be appended to exec.Cmd.Env, so
+// that a child process sees the same directories, even if it does not
+// implement the defaults itself.
+func (b *BaseDirs) ExportEnv() []string {
+	var env []string
+	add := func(key, value string) {
+		if value != "" {
+			env = append(env, key+"="+value)
+		}
+	}
+	list := string(os.PathListSeparator)
+	add("XDG_CONFIG_HOME", b.ConfigHome)
+	add("XDG_DATA_HOME", b.DataHome)
+	add("XDG_CACHE_HOME", b.CacheHome)
+	add("XDG_STATE_HOME", b.StateHome)
+	add("XDG_BIN_HOME", b.BinHome)
+	add("XDG_RUNTIME_DIR", b.RuntimeDir)
+	add("XDG_CONFIG_DIRS", strings.Join(b.ConfigDirs, list))
+	add("XDG_DATA_DIRS", strings.Join(b.DataDirs, list))
+	return env
+}
+
+// ExportShell returns the variables of ExportEnv as commands for shell,
+// one per line, which a wrapper script can evaluate. The shells "fish",
+// "powershell", and "pwsh" have their own syntax; for all others, such as
+// "sh", "bash", and "zsh", POSIX shell syntax is used:
+//
+//	export XDG_CONFIG_HOME='/home/ben/.config'
+func (b *BaseDirs) ExportShell(shell string) string {
+	var sb strings.Builder
+	for _, kv := range b.ExportEnv() {
+		k, v, _ := strings.Cut(kv, "=")
+		switch shell {
+		case "fish":
+			v = strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(v)
+			sb.WriteString("set -gx " + k + " '" + v + "'\n")
+		case "powershell", "pwsh":
+			v = strings.ReplaceAll(v, `'`, `''`)
+			sb.WriteString("$env:" + k + " = '" + v + "'\n")
+		default:
+			v = strings.ReplaceAll(v, `'`, `'\''`)
+			sb.WriteString("export " + k + "='" + v + "'\n")
+		}
+	}
+	return sb.String()
+}
+
+func ExportEnv() []string             { return defaults().ExportEnv() }
+func ExportShell(shell string) string { return defaults().ExportShell(shell) }
@@ -0,0 +1,59 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"io/fs"
+	"os"
+)
+
+// FindFileExt searches for base+ext in the base directories of category,
+// for each extension in exts, and returns the first file that exists and the
+// extension that matched. For example:
+//
+//	p, ext, err := xdg.FindConfigFileExt("dromi/config", ".toml", ".yaml", ".json")
+//
+// Each base directory is searched for all extensions before the next one,
+// so a file in a preferred base directory is found even if another base
+// directory contains a file with an extension that comes earlier in exts.
+//
+// If no file exists, the error wraps fs.ErrNotExist.
+func (b *BaseDirs) FindFileExt(category, base string, exts ...string) (path, ext string, err error) {
+	if _, ok := b.category(category); !ok {
+		return "", "", ErrUnknownCategory
+	}
+	if !validFile(base) {
+		return "", "", errInvalidFile("find", base)
+	}
+	for _, dir := range b.Paths(category) {
+		for _, ext := range exts {
+			p := join(dir, base+ext)
+			if p == "" {
+				continue
+			}
+			if _, err := os.Stat(p); err == nil {
+				return p, ext, nil
+			}
+		}
+	}
+	return "", "", &fs.PathError{Op: "find", Path: base, Err: fs.ErrNotExist}
+}
+
+func (b *BaseDirs) FindConfigFileExt(base string, exts ...string) (string, string, error) {
+	return b.FindFileExt("config", base, exts...)
+}
+func (b *BaseDirs) FindDataFileExt(base string, exts ...string) (string, string, error) {
+	return b.FindFileExt("data", base, exts...)
+}
+
+func FindFileExt(category, base string, exts ...string) (string, string, error) {
+	return defaults().FindFileExt(category, base, exts...)
+}
+func FindConfigFileExt(base string, exts ...string) (string, string, error) {
+	return defaults().FindConfigFileExt(base, exts...)
+}
+func FindDataFileExt(base string, exts ...string) (string, string, error) {
+	return defaults().FindDataFileExt(base, exts...)
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+)
+
+// ErrNotFIFO is returned by RuntimeFIFO if the file exists, but is not a
+// named pipe.
+var ErrNotFIFO = errors.New("file exists but is not a FIFO")
+
+// RuntimeFIFO creates the named pipe file in RuntimeDir with the
+// permissions perm, unless it already exists, and returns its path. The
+// directories leading to it are created with mode 0700. If file exists but
+// is not a named pipe, the error wraps ErrNotFIFO and the file is left
+// alone. A named pipe that RuntimeFIFO creates is tracked for CleanupRuntime.
+//
+// Named pipes are not supported on all systems; on those, the error wraps
+// errors.ErrUnsupported.
+func (b *BaseDirs) RuntimeFIFO(file string, perm fs.FileMode) (string, error) {
+	p, err := b.runtimePath("mkfifo", file)
+	if err != nil {
+		return "", err
+	}
+	err = mkfifo(p, perm)
+	if errors.Is(err, fs.ErrExist) {
+		fi, serr := os.Lstat(p)
+		if serr != nil {
+			return "", serr
+		}
+		if fi.Mode()&fs.ModeNamedPipe == 0 {
+			return "", &os.PathError{Op: "mkfifo", Path: p, Err: ErrNotFIFO}
+		}
+		return p, nil
+	}
+	if err == nil {
+		err = b.chown(p)
+	}
+	if err != nil {
+		return "", err
+	}
+	TrackRuntimeFile(p)
+	return p, nil
+}
+
+func RuntimeFIFO(file string, perm fs.FileMode) (string, error) {
+	return defaults().RuntimeFIFO(file, perm)
+}
@@ -0,0 +1,22 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package xdg
+
+import (
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+func mkfifo(p string, perm fs.FileMode) error {
+	if err := syscall.Mkfifo(p, uint32(perm.Perm())); err != nil {
+		return &os.PathError{Op: "mkfifo", Path: p, Err: err}
+	}
+	// Mkfifo is subject to the umask, unlike the permissions that the
+	// caller asked for.
+	return os.Chmod(p, perm.Perm())
+}
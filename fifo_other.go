@@ -0,0 +1,17 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package xdg
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+)
+
+func mkfifo(p string, perm fs.FileMode) error {
+	return &os.PathError{Op: "mkfifo", Path: p, Err: errors.ErrUnsupported}
+}
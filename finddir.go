@@ -0,0 +1,51 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import "os"
+
+// FindDir returns the absolute path of the first directory dir of category
+// that exists, such as "dromi/templates", searching the base directories in
+// order of preference. Unlike Find, files that are not directories are
+// ignored. If no directory is found, "" is returned.
+func (b *BaseDirs) FindDir(category, dir string) string {
+	if ds := b.findDirs(category, dir, true); len(ds) > 0 {
+		return ds[0]
+	}
+	return ""
+}
+
+// FindAllDirs returns the absolute paths of all directories dir of category
+// that exist, in order of preference, e.g. to load plugins from each of them.
+func (b *BaseDirs) FindAllDirs(category, dir string) []string {
+	return b.findDirs(category, dir, false)
+}
+
+func (b *BaseDirs) findDirs(category, dir string, first bool) []string {
+	var ds []string
+	for _, base := range b.Paths(category) {
+		p := join(base, dir)
+		if fi, err := os.Stat(p); err != nil || !fi.IsDir() {
+			continue
+		}
+		ds = append(ds, p)
+		if first {
+			break
+		}
+	}
+	return ds
+}
+
+func (b *BaseDirs) FindConfigDir(dir string) string       { return b.FindDir("config", dir) }
+func (b *BaseDirs) FindDataDir(dir string) string         { return b.FindDir("data", dir) }
+func (b *BaseDirs) FindAllConfigDirs(dir string) []string { return b.FindAllDirs("config", dir) }
+func (b *BaseDirs) FindAllDataDirs(dir string) []string   { return b.FindAllDirs("data", dir) }
+
+func FindDir(category, dir string) string       { return defaults().FindDir(category, dir) }
+func FindAllDirs(category, dir string) []string { return defaults().FindAllDirs(category, dir) }
+func FindConfigDir(dir string) string           { return defaults().FindConfigDir(dir) }
+func FindDataDir(dir string) string             { return defaults().FindDataDir(dir) }
+func FindAllConfigDirs(dir string) []string     { return defaults().FindAllConfigDirs(dir) }
+func FindAllDataDirs(dir string) []string       { return defaults().FindAllDataDirs(dir) }
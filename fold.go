@@ -0,0 +1,92 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// FindFold is like Find, but matches the elements of file case-insensitively,
+// so that "dromi/Config.toml" also finds dromi/config.toml. This is useful
+// for data files that are installed with inconsistent casing.
+//
+// Within a base directory, a file that matches exactly is preferred. If
+// several entries of a directory only match case-insensitively, the first
+// one in lexical order is used. The returned path has the casing of the
+// file that was found.
+func (b *BaseDirs) FindFold(category, file string) string {
+	for _, dir := range b.Paths(category) {
+		if p := lookupFold(dir, file); p != "" {
+			return p
+		}
+	}
+	return ""
+}
+
+// FindAllFold is like FindAll, but matches the elements of file
+// case-insensitively, in the same way as FindFold.
+func (b *BaseDirs) FindAllFold(category, file string) []string {
+	var ps []string
+	for _, dir := range b.Paths(category) {
+		if p := lookupFold(dir, file); p != "" {
+			ps = append(ps, p)
+		}
+	}
+	return ps
+}
+
+func (b *BaseDirs) FindConfigFold(file string) string      { return b.FindFold("config", file) }
+func (b *BaseDirs) FindDataFold(file string) string        { return b.FindFold("data", file) }
+func (b *BaseDirs) FindAllConfigFold(file string) []string { return b.FindAllFold("config", file) }
+func (b *BaseDirs) FindAllDataFold(file string) []string   { return b.FindAllFold("data", file) }
+
+func FindFold(category, file string) string      { return defaults().FindFold(category, file) }
+func FindAllFold(category, file string) []string { return defaults().FindAllFold(category, file) }
+func FindConfigFold(file string) string          { return defaults().FindConfigFold(file) }
+func FindDataFold(file string) string            { return defaults().FindDataFold(file) }
+func FindAllConfigFold(file string) []string     { return defaults().FindAllConfigFold(file) }
+func FindAllDataFold(file string) []string       { return defaults().FindAllDataFold(file) }
+
+// lookupFold returns the path of file in dir, matching each element of file
+// case-insensitively if it does not exist exactly, or "" if there is none.
+func lookupFold(dir, file string) string {
+	p := join(dir, file)
+	if p == "" {
+		return ""
+	}
+	if _, err := os.Stat(p); err == nil {
+		return p
+	}
+
+	cur := dir
+	// On Windows, backslashes separate elements as well, as in validFile.
+	for _, s := range strings.Split(path.Clean(filepath.ToSlash(file)), "/") {
+		next := filepath.Join(cur, s)
+		if _, err := os.Lstat(next); err != nil {
+			es, err := os.ReadDir(cur)
+			if err != nil {
+				return ""
+			}
+			next = ""
+			for _, e := range es {
+				if strings.EqualFold(e.Name(), s) {
+					next = filepath.Join(cur, e.Name())
+					break
+				}
+			}
+			if next == "" {
+				return ""
+			}
+		}
+		cur = next
+	}
+	if _, err := os.Stat(cur); err != nil {
+		return ""
+	}
+	return cur
+}
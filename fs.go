@@ -0,0 +1,173 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+)
+
+// FS returns a read-only file system that is the union of the base
+// directories of category, in order of preference. Opening a file returns
+// the first one found, as Find does; reading a directory returns the
+// entries of the directory in all base directories, where an entry in a
+// preferred directory hides entries of the same name in the others.
+//
+// The base directories are determined when FS is called.
+func (b *BaseDirs) FS(category string) fs.FS { return unionFS(b.Paths(category)) }
+
+func (b *BaseDirs) ConfigFS() fs.FS  { return b.FS("config") }
+func (b *BaseDirs) DataFS() fs.FS    { return b.FS("data") }
+func (b *BaseDirs) CacheFS() fs.FS   { return b.FS("cache") }
+func (b *BaseDirs) StateFS() fs.FS   { return b.FS("state") }
+func (b *BaseDirs) RuntimeFS() fs.FS { return b.FS("runtime") }
+
+func FS(category string) fs.FS { return defaults().FS(category) }
+func ConfigFS() fs.FS          { return defaults().ConfigFS() }
+func DataFS() fs.FS            { return defaults().DataFS() }
+func CacheFS() fs.FS           { return defaults().CacheFS() }
+func StateFS() fs.FS           { return defaults().StateFS() }
+func RuntimeFS() fs.FS         { return defaults().RuntimeFS() }
+
+// unionFS is a file system consisting of preference ordered directories.
+type unionFS []string
+
+func (u unionFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	for _, dir := range u {
+		f, err := os.Open(join(dir, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if fi, err := f.Stat(); err == nil && fi.IsDir() {
+			return &unionDir{File: f, fs: u, name: name}, nil
+		}
+		return f, nil
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// unionDir is a directory opened in a unionFS, which lists the entries
+// of the directory in all base directories.
+type unionDir struct {
+	*os.File
+	fs      unionFS
+	name    string
+	entries []fs.DirEntry
+	read    bool
+}
+
+func (d *unionDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.read {
+		es, err := d.fs.ReadDir(d.name)
+		if err != nil {
+			return nil, err
+		}
+		d.entries, d.read = es, true
+	}
+	if n <= 0 {
+		es := d.entries
+		d.entries = nil
+		return es, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(d.entries) {
+		n = len(d.entries)
+	}
+	es := d.entries[:n]
+	d.entries = d.entries[n:]
+	return es, nil
+}
+
+func (u unionFS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+	for _, dir := range u {
+		fi, err := os.Stat(join(dir, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		return fi, err
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+func (u unionFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	var (
+		found   bool
+		entries []fs.DirEntry
+		seen    = make(map[string]bool)
+	)
+	for _, dir := range u {
+		es, err := os.ReadDir(join(dir, name))
+		if err != nil {
+			// Directories that do not exist or cannot be read in one of the
+			// base directories are skipped, as with Find.
+			continue
+		}
+		found = true
+		for _, e := range es {
+			if !seen[e.Name()] {
+				seen[e.Name()] = true
+				entries = append(entries, e)
+			}
+		}
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// Walk walks the file tree rooted at root in all base directories of
+// category, calling fn for each file or directory, in the same way as
+// fs.WalkDir on FS(category). The trees are merged: a file in a preferred
+// base directory hides files with the same relative path in the others,
+// and fn is called once for each relative path.
+//
+// The path passed to fn is relative to the base directories; use Find to
+// get the absolute path, or open it with FS.
+func (b *BaseDirs) Walk(category, root string, fn fs.WalkDirFunc) error {
+	return fs.WalkDir(b.FS(category), root, func(path string, d fs.DirEntry, err error) error {
+		err = fn(path, d, err)
+		// fs.WalkDir only recognizes the sentinels if they are not wrapped.
+		switch {
+		case errors.Is(err, fs.SkipDir):
+			return fs.SkipDir
+		case errors.Is(err, fs.SkipAll):
+			return fs.SkipAll
+		}
+		return err
+	})
+}
+
+func (b *BaseDirs) WalkConfigFiles(root string, fn fs.WalkDirFunc) error {
+	return b.Walk("config", root, fn)
+}
+func (b *BaseDirs) WalkDataFiles(root string, fn fs.WalkDirFunc) error {
+	return b.Walk("data", root, fn)
+}
+
+func Walk(category, root string, fn fs.WalkDirFunc) error {
+	return defaults().Walk(category, root, fn)
+}
+func WalkConfigFiles(root string, fn fs.WalkDirFunc) error {
+	return defaults().WalkConfigFiles(root, fn)
+}
+func WalkDataFiles(root string, fn fs.WalkDirFunc) error { return defaults().WalkDataFiles(root, fn) }
@@ -0,0 +1,49 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+// The Get* methods return a base directory of b together with an error if
+// it could not be resolved, so that callers cannot forget to check for an
+// empty string. The error is the one recorded during resolution, see ErrFor.
+//
+// For ConfigDirs and DataDirs, the error is non-nil if any element of the
+// environment variable was ignored, but the remaining directories are
+// returned nevertheless. The returned slice is a copy.
+
+func (b *BaseDirs) GetConfigHome() (string, error) { return b.get(b.ConfigHome, "XDG_CONFIG_HOME") }
+func (b *BaseDirs) GetDataHome() (string, error)   { return b.get(b.DataHome, "XDG_DATA_HOME") }
+func (b *BaseDirs) GetCacheHome() (string, error)  { return b.get(b.CacheHome, "XDG_CACHE_HOME") }
+func (b *BaseDirs) GetStateHome() (string, error)  { return b.get(b.StateHome, "XDG_STATE_HOME") }
+func (b *BaseDirs) GetBinHome() (string, error)    { return b.get(b.BinHome, "XDG_BIN_HOME") }
+func (b *BaseDirs) GetRuntimeDir() (string, error) { return b.get(b.RuntimeDir, "XDG_RUNTIME_DIR") }
+func (b *BaseDirs) GetConfigDirs() ([]string, error) {
+	return combine("", b.ConfigDirs), b.ErrFor("XDG_CONFIG_DIRS")
+}
+func (b *BaseDirs) GetDataDirs() ([]string, error) {
+	return combine("", b.DataDirs), b.ErrFor("XDG_DATA_DIRS")
+}
+
+func (b *BaseDirs) get(dir, env string) (string, error) {
+	if dir != "" {
+		return dir, nil
+	}
+	if err := b.ErrFor(env); err != nil {
+		return "", err
+	}
+	return "", errUnresolved(env)
+}
+
+// The Get* functions return a base directory of the default BaseDirs in the
+// same way. Unlike the package variables, they are safe to use concurrently
+// with Reload, and they initialize the package if necessary.
+
+func GetConfigHome() (string, error)   { return defaults().GetConfigHome() }
+func GetDataHome() (string, error)     { return defaults().GetDataHome() }
+func GetCacheHome() (string, error)    { return defaults().GetCacheHome() }
+func GetStateHome() (string, error)    { return defaults().GetStateHome() }
+func GetBinHome() (string, error)      { return defaults().GetBinHome() }
+func GetRuntimeDir() (string, error)   { return defaults().GetRuntimeDir() }
+func GetConfigDirs() ([]string, error) { return defaults().GetConfigDirs() }
+func GetDataDirs() ([]string, error)   { return defaults().GetDataDirs() }
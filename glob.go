@@ -0,0 +1,51 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"io/fs"
+	"os"
+)
+
+// FindGlob returns the absolute paths of the files of category that match
+// pattern, which has the syntax of path.Match, e.g. "dromi/conf.d/*.toml".
+// The base directories are searched in order of preference, and a file is
+// only returned from the first base directory that contains a file with
+// the same relative path, so that user files hide global ones. Within each
+// base directory, the matches are sorted.
+//
+// If pattern is malformed, nil is returned.
+func (b *BaseDirs) FindGlob(category, pattern string) []string {
+	return findGlob(pattern, b.Paths(category))
+}
+
+func (b *BaseDirs) FindConfigGlob(pattern string) []string { return b.FindGlob("config", pattern) }
+func (b *BaseDirs) FindDataGlob(pattern string) []string   { return b.FindGlob("data", pattern) }
+
+func FindGlob(category, pattern string) []string { return defaults().FindGlob(category, pattern) }
+func FindConfigGlob(pattern string) []string     { return defaults().FindConfigGlob(pattern) }
+func FindDataGlob(pattern string) []string       { return defaults().FindDataGlob(pattern) }
+
+func findGlob(pattern string, paths []string) []string {
+	var (
+		ps   []string
+		seen = make(map[string]bool)
+	)
+	for _, dir := range paths {
+		// Matching relative to dir means that special characters in dir
+		// do not need to be escaped.
+		ms, err := fs.Glob(os.DirFS(dir), pattern)
+		if err != nil {
+			return nil
+		}
+		for _, m := range ms {
+			if !seen[m] {
+				seen[m] = true
+				ps = append(ps, join(dir, m))
+			}
+		}
+	}
+	return ps
+}
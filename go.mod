@@ -1,3 +1,7 @@
 module github.com/goulash/xdg
 
-go 1.14
+go 1.23
+
+require github.com/BurntSushi/toml v1.6.0
+
+require gopkg.in/yaml.v3 v3.0.1
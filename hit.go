@@ -0,0 +1,196 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"sync"
+)
+
+// Hit describes a file that was found in one of the base directories.
+type Hit struct {
+	Path string      // absolute path of the file
+	Dir  string      // base directory in which the file was found
+	Rank int         // index of Dir in the preference ordered base directories
+	Info fs.FileInfo // information about the file, as returned by os.Stat
+}
+
+// HitFunc is given to the MergeHits* functions to handle the files that they
+// find. It is like MergeFunc, but also receives the base directory of the
+// file, its rank, and its FileInfo, so that it can report where a value was
+// set, or compare modification times.
+type HitFunc func(h Hit) error
+
+// FindHits is like FindAll, but returns a Hit for each file, so that the
+// caller can tell where each file was found, for example to report that
+// ~/.config/dromi/config.toml overrides /etc/xdg/dromi/config.toml.
+func (b *BaseDirs) FindHits(category, file string) []Hit {
+	return findHits(file, b.Paths(category))
+}
+
+func (b *BaseDirs) FindConfigAll(file string) []Hit { return b.FindHits("config", file) }
+func (b *BaseDirs) FindDataAll(file string) []Hit   { return b.FindHits("data", file) }
+
+func FindHits(category, file string) []Hit { return defaults().FindHits(category, file) }
+func FindConfigAll(file string) []Hit      { return defaults().FindConfigAll(file) }
+func FindDataAll(file string) []Hit        { return defaults().FindDataAll(file) }
+
+// FindNewest is like Find, but returns the file of category that was
+// modified most recently, regardless of the precedence of its base
+// directory. If several files have the same modification time, the
+// preferred one is returned.
+func (b *BaseDirs) FindNewest(category, file string) string {
+	var newest *Hit
+	hs := b.FindHits(category, file)
+	for i := range hs {
+		if newest == nil || hs[i].Info.ModTime().After(newest.Info.ModTime()) {
+			newest = &hs[i]
+		}
+	}
+	if newest == nil {
+		return ""
+	}
+	return newest.Path
+}
+
+func (b *BaseDirs) FindConfigNewest(file string) string { return b.FindNewest("config", file) }
+func (b *BaseDirs) FindDataNewest(file string) string   { return b.FindNewest("data", file) }
+
+func FindNewest(category, file string) string { return defaults().FindNewest(category, file) }
+func FindConfigNewest(file string) string     { return defaults().FindConfigNewest(file) }
+func FindDataNewest(file string) string       { return defaults().FindDataNewest(file) }
+
+// Shadowed returns the files of category with the relative path file that
+// are hidden by a file in a preferred base directory, i.e. all files that
+// FindAll returns except the first. If at most one file exists, nil is
+// returned. For example, it returns /etc/xdg/dromi.conf if
+// ~/.config/dromi.conf exists as well.
+func (b *BaseDirs) Shadowed(category, file string) ([]string, error) {
+	if _, ok := b.category(category); !ok {
+		return nil, ErrUnknownCategory
+	}
+	if !validFile(file) {
+		return nil, errInvalidFile("find", file)
+	}
+	ps := b.FindAll(category, file)
+	if len(ps) < 2 {
+		return nil, nil
+	}
+	return ps[1:], nil
+}
+
+func Shadowed(category, file string) ([]string, error) {
+	return defaults().Shadowed(category, file)
+}
+
+// MergeHits is like Merge, but calls f with a Hit for each file.
+func (b *BaseDirs) MergeHits(category, file string, f HitFunc) error {
+	if _, ok := b.category(category); !ok {
+		return ErrUnknownCategory
+	}
+	return mergeHits(findHits(file, b.Paths(category)), f)
+}
+
+// MergeHitsR is like MergeR, but calls f with a Hit for each file.
+func (b *BaseDirs) MergeHitsR(category, file string, f HitFunc) error {
+	if _, ok := b.category(category); !ok {
+		return ErrUnknownCategory
+	}
+	hs := findHits(file, b.Paths(category))
+	for i, j := 0, len(hs)-1; i < j; i, j = i+1, j-1 {
+		hs[i], hs[j] = hs[j], hs[i]
+	}
+	return mergeHits(hs, f)
+}
+
+func (b *BaseDirs) MergeConfigHits(file string, f HitFunc) error {
+	return b.MergeHits("config", file, f)
+}
+func (b *BaseDirs) MergeConfigHitsR(file string, f HitFunc) error {
+	return b.MergeHitsR("config", file, f)
+}
+func (b *BaseDirs) MergeDataHits(file string, f HitFunc) error {
+	return b.MergeHits("data", file, f)
+}
+func (b *BaseDirs) MergeDataHitsR(file string, f HitFunc) error {
+	return b.MergeHitsR("data", file, f)
+}
+
+func MergeHits(category, file string, f HitFunc) error {
+	return defaults().MergeHits(category, file, f)
+}
+func MergeHitsR(category, file string, f HitFunc) error {
+	return defaults().MergeHitsR(category, file, f)
+}
+func MergeConfigHits(file string, f HitFunc) error  { return defaults().MergeConfigHits(file, f) }
+func MergeConfigHitsR(file string, f HitFunc) error { return defaults().MergeConfigHitsR(file, f) }
+func MergeDataHits(file string, f HitFunc) error    { return defaults().MergeDataHits(file, f) }
+func MergeDataHitsR(file string, f HitFunc) error   { return defaults().MergeDataHitsR(file, f) }
+
+// findHits returns a Hit for each file that exists, in the order of paths.
+// If Parallelism is greater than 1, the paths are probed concurrently.
+// If CacheLookups is true, the result is cached.
+func findHits(file string, paths []string) []Hit {
+	if CacheLookups {
+		if hs, ok := cachedHits(file, paths); ok {
+			return hs
+		}
+	}
+	fis := make([]fs.FileInfo, len(paths))
+	if n := Parallelism; n > 1 && len(paths) > 1 {
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, n)
+		for i, dir := range paths {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer wg.Done()
+				fis[i], _ = statFile(dir, file)
+				<-sem
+			}()
+		}
+		wg.Wait()
+	} else {
+		for i, dir := range paths {
+			fis[i], _ = statFile(dir, file)
+		}
+	}
+
+	hs := make([]Hit, 0, len(paths))
+	for i, fi := range fis {
+		if fi != nil {
+			hs = append(hs, Hit{Path: join(paths[i], file), Dir: paths[i], Rank: i, Info: fi})
+		}
+	}
+	if CacheLookups {
+		storeHits(file, paths, hs)
+	}
+	return hs
+}
+
+// statFile returns the FileInfo of file in dir.
+func statFile(dir, file string) (fs.FileInfo, error) {
+	p := join(dir, file)
+	if p == "" {
+		return nil, errInvalidFile("stat", file)
+	}
+	return os.Stat(p)
+}
+
+// mergeHits calls f on each of hs in order, in the same way as mergeFiles.
+func mergeHits(hs []Hit, f HitFunc) error {
+	var err error
+	for _, h := range hs {
+		if err = f(h); err != nil {
+			break
+		}
+	}
+	if errors.Is(err, Skip) {
+		return nil
+	}
+	return err
+}
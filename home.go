@@ -0,0 +1,78 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"os/user"
+	"path/filepath"
+)
+
+// HomeSource describes where the home directory was found.
+type HomeSource int
+
+const (
+	// HomeNone means that no valid home directory was found, so that the
+	// defaults that depend on it could not be resolved.
+	HomeNone HomeSource = iota
+
+	// HomeEnv means that the home directory was read from $HOME.
+	HomeEnv
+
+	// HomeProfile means that the home directory was read from
+	// %USERPROFILE%, on Windows.
+	HomeProfile
+
+	// HomeUser means that $HOME was not set, as is common for cron jobs and
+	// some service managers, and that the home directory of the user of the
+	// process was looked up with os/user.
+	HomeUser
+
+	// HomeSudo means that the home directory is that of the user who
+	// invoked sudo; see Sudo.
+	HomeSudo
+
+	// HomeTemp means that a directory in TempDir stands in for the home
+	// directory, on WebAssembly hosts that provide none.
+	HomeTemp
+
+	// HomeFallbackRoot means that FallbackRoot stands in for the home
+	// directory.
+	HomeFallbackRoot
+
+	// HomeOption means that the home directory was given with WithHome.
+	HomeOption
+)
+
+func (s HomeSource) String() string {
+	switch s {
+	case HomeNone:
+		return "none"
+	case HomeEnv:
+		return "HOME"
+	case HomeProfile:
+		return "USERPROFILE"
+	case HomeUser:
+		return "user"
+	case HomeSudo:
+		return "SUDO_USER"
+	case HomeTemp:
+		return "temp"
+	case HomeFallbackRoot:
+		return "FallbackRoot"
+	case HomeOption:
+		return "option"
+	}
+	return "unknown"
+}
+
+// currentUserHome returns the home directory of the user of the process,
+// as os/user reports it, or "" if it is unknown.
+func currentUserHome() string {
+	u, err := user.Current()
+	if err != nil || !filepath.IsAbs(u.HomeDir) {
+		return ""
+	}
+	return u.HomeDir
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"io"
+	"net"
+	"os"
+	"time"
+)
+
+// InstanceLock guarantees that only one instance of an application runs per
+// user session. It is held until Release is called or the process exits.
+// The instance that holds the lock receives messages from other instances,
+// such as "raise window" or the files to open, with Accept.
+type InstanceLock struct {
+	f *os.File
+	l net.Listener
+}
+
+// maxInstanceMessage is the maximum size of a message that Accept reads.
+const maxInstanceMessage = 1 << 16
+
+// AcquireInstanceLock acquires the instance lock of the application app,
+// which is kept in the directory app in RuntimeDir. If another instance
+// holds the lock, ErrLocked is returned, and the caller may use
+// SendInstanceMessage to pass its request to that instance and exit.
+func (b *BaseDirs) AcquireInstanceLock(app string) (*InstanceLock, error) {
+	p, err := b.runtimePath("lock", app+"/instance.lock")
+	if err != nil {
+		return nil, err
+	}
+	f, err := b.openCreate(p)
+	if err != nil {
+		return nil, err
+	}
+	if err := flock(f, false); err != nil {
+		f.Close()
+		return nil, err
+	}
+	l, err := b.ListenRuntimeSocket(app + "/instance.sock")
+	if err != nil {
+		funlock(f)
+		f.Close()
+		return nil, err
+	}
+	return &InstanceLock{f: f, l: l}, nil
+}
+
+// Accept waits for the next message from another instance and returns it.
+// After Release, it returns an error wrapping net.ErrClosed.
+func (l *InstanceLock) Accept() (string, error) {
+	for {
+		c, err := l.l.Accept()
+		if err != nil {
+			return "", err
+		}
+		c.SetReadDeadline(time.Now().Add(5 * time.Second))
+		msg, err := io.ReadAll(io.LimitReader(c, maxInstanceMessage))
+		c.Close()
+		if err == nil {
+			return string(msg), nil
+		}
+		// A misbehaving client must not prevent others from being served.
+	}
+}
+
+// Release releases the lock and stops accepting messages.
+func (l *InstanceLock) Release() error {
+	err := l.l.Close()
+	if uerr := funlock(l.f); err == nil {
+		err = uerr
+	}
+	if cerr := l.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// SendInstanceMessage sends msg to the instance of app that holds the
+// instance lock.
+func (b *BaseDirs) SendInstanceMessage(app, msg string) error {
+	c, err := b.DialRuntimeSocket(app + "/instance.sock")
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(c, msg)
+	if cerr := c.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func AcquireInstanceLock(app string) (*InstanceLock, error) {
+	return defaults().AcquireInstanceLock(app)
+}
+func SendInstanceMessage(app, msg string) error { return defaults().SendInstanceMessage(app, msg) }
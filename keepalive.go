@@ -0,0 +1,53 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// KeepAlive keeps the file p in the runtime directory from being removed by
+// periodic clean-up, which the specification allows for files whose access
+// time has not been modified for 6 hours. It sets the access time of p now
+// and then every interval, until stop is called; if interval is not
+// positive, it is one hour. Errors, e.g. because p has been removed, are
+// ignored. The function stop may be called more than once.
+//
+// Alternatively, Persist marks a file so that it is not removed at all.
+func KeepAlive(p string, interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	touch := func() { os.Chtimes(p, time.Now(), time.Time{}) }
+	touch()
+
+	done := make(chan struct{})
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				touch()
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// Persist sets the sticky bit on the file p in the runtime directory, which
+// exempts it from periodic clean-up according to the specification.
+func Persist(p string) error {
+	fi, err := os.Stat(p)
+	if err != nil {
+		return err
+	}
+	return os.Chmod(p, fi.Mode()|os.ModeSticky)
+}
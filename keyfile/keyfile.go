@@ -0,0 +1,290 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+// Package keyfile reads and writes files in the key file format of the
+// freedesktop.org Desktop Entry specification, which is also used by
+// mimeapps.list and many other files found in the XDG base directories:
+//
+//	# A comment
+//	[Desktop Entry]
+//	Name=Dromi
+//	Name[de]=Dromi
+//	Categories=Audio;Player;
+//
+// Comments and the order of groups and keys are preserved when a File is
+// written again. Values are stored as they appear in the file; String,
+// Strings, and LocaleString interpret the escape sequences and lists that
+// the specification defines.
+//
+// Like GLib, the parser is lenient: a group that occurs more than once is
+// merged into its first occurrence, and the last value of a repeated key
+// is used.
+package keyfile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// File is a parsed key file. The zero value is an empty file.
+type File struct {
+	header []string // comments of a file without groups
+	groups []*section
+}
+
+// section is a group in a File.
+type section struct {
+	name     string
+	comments []string
+	entries  []*entry
+}
+
+type entry struct {
+	key      string
+	value    string
+	comments []string
+}
+
+// SyntaxError is returned by Parse if a line is malformed.
+type SyntaxError struct {
+	Line int    // line number, starting at 1
+	Msg  string // description of the error
+}
+
+func (e *SyntaxError) Error() string { return fmt.Sprintf("keyfile: line %d: %s", e.Line, e.Msg) }
+
+// New returns an empty File.
+func New() *File { return &File{} }
+
+// Parse reads a key file from r.
+func Parse(r io.Reader) (*File, error) {
+	f := New()
+	var (
+		g        *section
+		comments []string
+		n        int
+	)
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		n++
+		line := strings.TrimSuffix(s.Text(), "\r")
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "" || trimmed[0] == '#':
+			comments = append(comments, line)
+		case trimmed[0] == '[':
+			if trimmed[len(trimmed)-1] != ']' {
+				return nil, &SyntaxError{n, "unterminated group header"}
+			}
+			name := trimmed[1 : len(trimmed)-1]
+			if !validGroup(name) {
+				return nil, &SyntaxError{n, fmt.Sprintf("invalid group name %q", name)}
+			}
+			if g = f.group(name); g == nil {
+				g = &section{name: name, comments: comments}
+				f.groups = append(f.groups, g)
+			} else {
+				g.comments = append(g.comments, comments...)
+			}
+			comments = nil
+		default:
+			i := strings.IndexByte(line, '=')
+			if i < 0 {
+				return nil, &SyntaxError{n, "expected group header or key=value"}
+			}
+			if g == nil {
+				return nil, &SyntaxError{n, "key outside of group"}
+			}
+			key := strings.TrimSpace(line[:i])
+			if key == "" {
+				return nil, &SyntaxError{n, "empty key"}
+			}
+			value := strings.TrimLeft(line[i+1:], " \t")
+			if e := g.entry(key); e != nil {
+				e.value = value
+				e.comments = append(e.comments, comments...)
+			} else {
+				g.entries = append(g.entries, &entry{key, value, comments})
+			}
+			comments = nil
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if g == nil {
+		f.header = comments
+	} else if len(comments) > 0 {
+		// Trailing comments are kept at the end of the last group.
+		g.entries = append(g.entries, &entry{comments: comments})
+	}
+	return f, nil
+}
+
+// ParseFile reads the key file name.
+func ParseFile(name string) (*File, error) {
+	fd, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+	f, err := Parse(fd)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return f, nil
+}
+
+// WriteTo writes f to w in the key file format.
+func (f *File) WriteTo(w io.Writer) (int64, error) {
+	var bw strings.Builder
+	for _, c := range f.header {
+		bw.WriteString(c + "\n")
+	}
+	for i, g := range f.groups {
+		if i > 0 && len(g.comments) == 0 {
+			bw.WriteString("\n")
+		}
+		for _, c := range g.comments {
+			bw.WriteString(c + "\n")
+		}
+		bw.WriteString("[" + g.name + "]\n")
+		for _, e := range g.entries {
+			for _, c := range e.comments {
+				bw.WriteString(c + "\n")
+			}
+			if e.key != "" {
+				bw.WriteString(e.key + "=" + e.value + "\n")
+			}
+		}
+	}
+	n, err := io.WriteString(w, bw.String())
+	return int64(n), err
+}
+
+// WriteFile writes f to the file name, creating it with perm if necessary.
+func (f *File) WriteFile(name string, perm os.FileMode) error {
+	var b strings.Builder
+	f.WriteTo(&b)
+	return os.WriteFile(name, []byte(b.String()), perm)
+}
+
+func validGroup(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if r == '[' || r == ']' || r < 0x20 || r == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *File) group(name string) *section {
+	for _, g := range f.groups {
+		if g.name == name {
+			return g
+		}
+	}
+	return nil
+}
+
+func (g *section) entry(key string) *entry {
+	for _, e := range g.entries {
+		if e.key == key {
+			return e
+		}
+	}
+	return nil
+}
+
+// Groups returns the names of the groups in f, in the order of the file.
+func (f *File) Groups() []string {
+	ns := make([]string, len(f.groups))
+	for i, g := range f.groups {
+		ns[i] = g.name
+	}
+	return ns
+}
+
+// HasGroup returns true if f contains the group name.
+func (f *File) HasGroup(name string) bool { return f.group(name) != nil }
+
+// Keys returns the keys in group, including localized keys such as
+// "Name[de]", in the order of the file.
+func (f *File) Keys(group string) []string {
+	g := f.group(group)
+	if g == nil {
+		return nil
+	}
+	var ks []string
+	for _, e := range g.entries {
+		if e.key != "" {
+			ks = append(ks, e.key)
+		}
+	}
+	return ks
+}
+
+// Value returns the raw value of key in group, without interpreting escape
+// sequences. If the key does not exist, ok is false.
+func (f *File) Value(group, key string) (value string, ok bool) {
+	g := f.group(group)
+	if g == nil {
+		return "", false
+	}
+	if e := g.entry(key); e != nil {
+		return e.value, true
+	}
+	return "", false
+}
+
+// SetValue sets the raw value of key in group, which is created if it does
+// not exist. The value must not contain a newline.
+func (f *File) SetValue(group, key, value string) {
+	g := f.group(group)
+	if g == nil {
+		g = &section{name: group}
+		f.groups = append(f.groups, g)
+	}
+	if e := g.entry(key); e != nil {
+		e.value = value
+		return
+	}
+	e := &entry{key: key, value: value}
+	// Keep trailing comments at the end of the group.
+	if n := len(g.entries); n > 0 && g.entries[n-1].key == "" {
+		g.entries = append(g.entries[:n-1], e, g.entries[n-1])
+	} else {
+		g.entries = append(g.entries, e)
+	}
+}
+
+// Delete removes key from group.
+func (f *File) Delete(group, key string) {
+	g := f.group(group)
+	if g == nil {
+		return
+	}
+	for i, e := range g.entries {
+		if e.key == key {
+			g.entries = append(g.entries[:i], g.entries[i+1:]...)
+			return
+		}
+	}
+}
+
+// DeleteGroup removes group and all its keys from f.
+func (f *File) DeleteGroup(group string) {
+	for i, g := range f.groups {
+		if g.name == group {
+			f.groups = append(f.groups[:i], f.groups[i+1:]...)
+			return
+		}
+	}
+}
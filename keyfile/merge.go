@@ -0,0 +1,64 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package keyfile
+
+import (
+	"io/fs"
+
+	"github.com/goulash/xdg"
+)
+
+// Merge merges g into f: groups of g that f does not contain are added,
+// and the values of keys in g replace those in f.
+func (f *File) Merge(g *File) {
+	for _, gg := range g.groups {
+		for _, e := range gg.entries {
+			if e.key != "" {
+				f.SetValue(gg.name, e.key, e.value)
+			}
+		}
+		if !f.HasGroup(gg.name) {
+			// The group is empty, but should still exist.
+			f.groups = append(f.groups, &section{name: gg.name})
+		}
+	}
+}
+
+// MergeFiles parses every file that xdg.FindAll finds in category and
+// merges them, so that the values in preferred files replace those in the
+// others. For example, MergeFiles("config", "mimeapps.list") combines
+// /etc/xdg/mimeapps.list and ~/.config/mimeapps.list. If no file is found,
+// an error wrapping fs.ErrNotExist is returned.
+func MergeFiles(category, file string) (*File, error) {
+	return mergeFiles(file, func(f xdg.MergeFunc) error { return xdg.MergeR(category, file, f) })
+}
+
+// MergeFilesDirs is like MergeFiles, but searches the base directories of b.
+func MergeFilesDirs(b *xdg.BaseDirs, category, file string) (*File, error) {
+	return mergeFiles(file, func(f xdg.MergeFunc) error { return b.MergeR(category, file, f) })
+}
+
+func mergeFiles(file string, mergeR func(xdg.MergeFunc) error) (*File, error) {
+	var f *File
+	err := mergeR(func(p string) error {
+		g, err := ParseFile(p)
+		if err != nil {
+			return err
+		}
+		if f == nil {
+			f = g
+		} else {
+			f.Merge(g)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if f == nil {
+		return nil, &fs.PathError{Op: "merge", Path: file, Err: fs.ErrNotExist}
+	}
+	return f, nil
+}
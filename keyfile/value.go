@@ -0,0 +1,194 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package keyfile
+
+import "strings"
+
+// String returns the value of key in group with the escape sequences \s,
+// \n, \t, \r, and \\ replaced.
+func (f *File) String(group, key string) (string, bool) {
+	v, ok := f.Value(group, key)
+	if !ok {
+		return "", false
+	}
+	return unescape(v), true
+}
+
+// SetString sets the value of key in group to s, escaping it as necessary.
+func (f *File) SetString(group, key, s string) { f.SetValue(group, key, escape(s, false)) }
+
+// Strings returns the value of key in group as a list of strings, which are
+// separated by semicolons. A trailing semicolon is optional, and "\;" is a
+// literal semicolon.
+func (f *File) Strings(group, key string) ([]string, bool) {
+	v, ok := f.Value(group, key)
+	if !ok {
+		return nil, false
+	}
+	var (
+		xs  []string
+		cur strings.Builder
+	)
+	for i := 0; i < len(v); i++ {
+		switch {
+		case v[i] == '\\' && i+1 < len(v):
+			cur.WriteString(v[i : i+2])
+			i++
+		case v[i] == ';':
+			xs = append(xs, unescape(cur.String()))
+			cur.Reset()
+		default:
+			cur.WriteByte(v[i])
+		}
+	}
+	if cur.Len() > 0 {
+		xs = append(xs, unescape(cur.String()))
+	}
+	return xs, true
+}
+
+// SetStrings sets the value of key in group to the list xs.
+func (f *File) SetStrings(group, key string, xs []string) {
+	var b strings.Builder
+	for _, x := range xs {
+		b.WriteString(escape(x, true))
+		b.WriteByte(';')
+	}
+	f.SetValue(group, key, b.String())
+}
+
+// Bool returns the value of key in group as a boolean. If the key does not
+// exist or its value is neither "true" nor "false", ok is false.
+func (f *File) Bool(group, key string) (value, ok bool) {
+	v, ok := f.Value(group, key)
+	switch {
+	case !ok:
+		return false, false
+	case v == "true":
+		return true, true
+	case v == "false":
+		return false, true
+	}
+	return false, false
+}
+
+// SetBool sets the value of key in group to "true" or "false".
+func (f *File) SetBool(group, key string, value bool) {
+	if value {
+		f.SetValue(group, key, "true")
+	} else {
+		f.SetValue(group, key, "false")
+	}
+}
+
+// LocaleString returns the value of key in group that best matches locale,
+// as String does. The locale has the POSIX form lang_COUNTRY.ENCODING@MODIFIER,
+// where all parts but lang are optional. The localized keys are tried in the
+// order that the specification defines, e.g. for "de_AT.UTF-8@euro":
+// Key[de_AT@euro], Key[de_AT], Key[de@euro], Key[de], and finally Key.
+func (f *File) LocaleString(group, key, locale string) (string, bool) {
+	for _, l := range locales(locale) {
+		if v, ok := f.String(group, key+"["+l+"]"); ok {
+			return v, true
+		}
+	}
+	return f.String(group, key)
+}
+
+// LocaleStrings returns the value of key in group that best matches locale,
+// in the same way as LocaleString, as a list of strings, as Strings does.
+func (f *File) LocaleStrings(group, key, locale string) ([]string, bool) {
+	for _, l := range locales(locale) {
+		if xs, ok := f.Strings(group, key+"["+l+"]"); ok {
+			return xs, true
+		}
+	}
+	return f.Strings(group, key)
+}
+
+// locales returns the locale suffixes to try for locale, in order.
+func locales(locale string) []string {
+	var modifier string
+	if i := strings.IndexByte(locale, '@'); i >= 0 {
+		locale, modifier = locale[:i], locale[i:]
+	}
+	if i := strings.IndexByte(locale, '.'); i >= 0 {
+		locale = locale[:i]
+	}
+	lang, country, _ := strings.Cut(locale, "_")
+	if lang == "" || lang == "C" || lang == "POSIX" {
+		return nil
+	}
+	var ls []string
+	if country != "" && modifier != "" {
+		ls = append(ls, lang+"_"+country+modifier)
+	}
+	if country != "" {
+		ls = append(ls, lang+"_"+country)
+	}
+	if modifier != "" {
+		ls = append(ls, lang+modifier)
+	}
+	return append(ls, lang)
+}
+
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 's':
+			b.WriteByte(' ')
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		default:
+			// Includes \\ and \; in lists.
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// escape escapes s so that unescape returns it again. Leading spaces are
+// escaped, since they would be ignored otherwise. If list is true,
+// semicolons are escaped as well.
+func escape(s string, list bool) string {
+	var b strings.Builder
+	leading := true
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != ' ' {
+			leading = false
+		}
+		switch {
+		case c == ' ' && leading:
+			b.WriteString(`\s`)
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c == '\t':
+			b.WriteString(`\t`)
+		case c == '\r':
+			b.WriteString(`\r`)
+		case c == '\\':
+			b.WriteString(`\\`)
+		case c == ';' && list:
+			b.WriteString(`\;`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+)
+
+// DecodeFunc decodes the contents of r into v. Most decoders of configuration
+// formats can be adapted easily, for example:
+//
+//	func(r io.Reader, v *Config) error { return json.NewDecoder(r).Decode(v) }
+type DecodeFunc[T any] func(r io.Reader, v *T) error
+
+// Load finds the configuration file that FindConfig finds, decodes it with
+// decode, and returns the result. If no file is found, an error wrapping
+// fs.ErrNotExist is returned. Errors returned by decode are wrapped with
+// the path of the file.
+func Load[T any](file string, decode DecodeFunc[T]) (T, error) {
+	var v T
+	p := FindConfig(file)
+	if p == "" {
+		return v, &fs.PathError{Op: "load", Path: file, Err: fs.ErrNotExist}
+	}
+	return v, decodeFile(p, &v, decode)
+}
+
+// LoadMerged decodes every configuration file that FindAllConfig finds into
+// the same value, from the least preferred file to the most preferred one,
+// so that values in user files override values in global files (as far as
+// decode leaves existing values alone). If no file is found, an error
+// wrapping fs.ErrNotExist is returned.
+func LoadMerged[T any](file string, decode DecodeFunc[T]) (T, error) {
+	var v T
+	ps := FindAllConfig(file)
+	if len(ps) == 0 {
+		return v, &fs.PathError{Op: "load", Path: file, Err: fs.ErrNotExist}
+	}
+	for _, p := range reverse(ps) {
+		if err := decodeFile(p, &v, decode); err != nil {
+			return v, err
+		}
+	}
+	return v, nil
+}
+
+func decodeFile[T any](path string, v *T, decode DecodeFunc[T]) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := decode(f, v); err != nil {
+		return fmt.Errorf("decode %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrLocked is returned by the TryLock* functions if the lock is held by
+// another process, or by another Lock in the same process.
+var ErrLocked = errors.New("file is locked")
+
+// Lock is an exclusive advisory lock on a file, which is held until Unlock
+// is called. It is advisory, so it only protects a file from processes that
+// also lock it, such as other instances of the same application.
+//
+// The lock is taken on a separate lock file: the file with the additional
+// extension ".lock" next to the file in the user base directory. If that
+// cannot be created, for example because the home directory is read-only,
+// the error is returned; there is no other location, so that all processes
+// always lock the same file. The lock file is not removed by Unlock, since
+// that would race with other processes that are about to lock it.
+//
+// Locks are only supported on systems with flock(2); on others, the Lock*
+// functions return an error wrapping errors.ErrUnsupported.
+type Lock struct {
+	f *os.File
+}
+
+// Path returns the path of the lock file.
+func (l *Lock) Path() string { return l.f.Name() }
+
+// Unlock releases the lock.
+func (l *Lock) Unlock() error {
+	err := funlock(l.f)
+	if cerr := l.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// LockFile locks file of category, waiting until the lock is available.
+func (b *BaseDirs) LockFile(category, file string) (*Lock, error) {
+	return b.lock(category, file, true)
+}
+
+// TryLockFile locks file of category, or returns ErrLocked if the lock is
+// held elsewhere.
+func (b *BaseDirs) TryLockFile(category, file string) (*Lock, error) {
+	return b.lock(category, file, false)
+}
+
+// LockFileContext locks file of category, waiting until the lock is
+// available or ctx is done, in which case the error of ctx is returned.
+func (b *BaseDirs) LockFileContext(ctx context.Context, category, file string) (*Lock, error) {
+	wait := 10 * time.Millisecond
+	for {
+		l, err := b.TryLockFile(category, file)
+		if err != ErrLocked {
+			return l, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+		if wait < 200*time.Millisecond {
+			wait *= 2
+		}
+	}
+}
+
+func (b *BaseDirs) lock(category, file string, block bool) (*Lock, error) {
+	f, err := b.openLockFile(category, file)
+	if err != nil {
+		return nil, err
+	}
+	if err := flock(f, block); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &Lock{f}, nil
+}
+
+// openLockFile opens the lock file of file, as described at Lock.
+func (b *BaseDirs) openLockFile(category, file string) (*os.File, error) {
+	d, ok := b.category(category)
+	if !ok {
+		return nil, ErrUnknownCategory
+	}
+	if !validFile(file) {
+		return nil, errInvalidFile("lock", file)
+	}
+
+	p := join(d.home, file+".lock")
+	if p == "" {
+		return nil, errUnresolved(d.env)
+	}
+	if err := b.mkdirAll(filepath.Dir(p), dirPerm(category)); err != nil {
+		return nil, err
+	}
+	return b.openCreate(p)
+}
+
+func (b *BaseDirs) LockConfigFile(file string) (*Lock, error) { return b.LockFile("config", file) }
+func (b *BaseDirs) LockStateFile(file string) (*Lock, error)  { return b.LockFile("state", file) }
+func (b *BaseDirs) TryLockConfigFile(file string) (*Lock, error) {
+	return b.TryLockFile("config", file)
+}
+func (b *BaseDirs) TryLockStateFile(file string) (*Lock, error) {
+	return b.TryLockFile("state", file)
+}
+func (b *BaseDirs) LockConfigFileContext(ctx context.Context, file string) (*Lock, error) {
+	return b.LockFileContext(ctx, "config", file)
+}
+func (b *BaseDirs) LockStateFileContext(ctx context.Context, file string) (*Lock, error) {
+	return b.LockFileContext(ctx, "state", file)
+}
+
+func LockFile(category, file string) (*Lock, error) { return defaults().LockFile(category, file) }
+func TryLockFile(category, file string) (*Lock, error) {
+	return defaults().TryLockFile(category, file)
+}
+func LockFileContext(ctx context.Context, category, file string) (*Lock, error) {
+	return FromContext(ctx).LockFileContext(ctx, category, file)
+}
+func LockConfigFile(file string) (*Lock, error)    { return defaults().LockConfigFile(file) }
+func LockStateFile(file string) (*Lock, error)     { return defaults().LockStateFile(file) }
+func TryLockConfigFile(file string) (*Lock, error) { return defaults().TryLockConfigFile(file) }
+func TryLockStateFile(file string) (*Lock, error)  { return defaults().TryLockStateFile(file) }
+func LockConfigFileContext(ctx context.Context, file string) (*Lock, error) {
+	return FromContext(ctx).LockConfigFileContext(ctx, file)
+}
+func LockStateFileContext(ctx context.Context, file string) (*Lock, error) {
+	return FromContext(ctx).LockStateFileContext(ctx, file)
+}
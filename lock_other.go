@@ -0,0 +1,20 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package xdg
+
+import (
+	"errors"
+	"os"
+)
+
+func flock(f *os.File, block bool) error {
+	return &os.PathError{Op: "flock", Path: f.Name(), Err: errors.ErrUnsupported}
+}
+
+func funlock(f *os.File) error {
+	return &os.PathError{Op: "funlock", Path: f.Name(), Err: errors.ErrUnsupported}
+}
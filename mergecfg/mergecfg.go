@@ -0,0 +1,154 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+// Package mergecfg merges configuration files found in the XDG base
+// directories semantically, instead of just iterating over their paths.
+//
+// Every configuration file that xdg.FindAllConfig finds is decoded into a
+// map, from the least preferred file (e.g. in /etc/xdg) to the most
+// preferred one (in $XDG_CONFIG_HOME), and the maps are merged recursively:
+// nested maps are merged key by key, and all other values, including
+// arrays, are replaced by the more preferred value. For example:
+//
+//	cfg, err := mergecfg.MergeJSON("dromi/config.json")
+//
+// JSON, TOML, and YAML files are supported; the format is either given explicitly
+// as a Codec, or chosen by the extension of the file.
+package mergecfg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"github.com/goulash/xdg"
+	"gopkg.in/yaml.v3"
+)
+
+// Codec decodes a configuration file into a map.
+type Codec func(r io.Reader) (map[string]any, error)
+
+// JSON decodes JSON configuration files.
+func JSON(r io.Reader) (map[string]any, error) {
+	var m map[string]any
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// TOML decodes TOML configuration files. Tables are decoded as maps, so
+// they are merged like JSON objects; arrays, including arrays of tables,
+// are replaced.
+func TOML(r io.Reader) (map[string]any, error) {
+	var m map[string]any
+	if _, err := toml.NewDecoder(r).Decode(&m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// YAML decodes YAML configuration files. Anchors, aliases, and merge keys
+// are resolved within each file before the files are merged. Mappings with
+// keys that are not all strings are not merged, but replaced.
+func YAML(r io.Reader) (map[string]any, error) {
+	var m map[string]any
+	if err := yaml.NewDecoder(r).Decode(&m); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Codecs maps file extensions to the codec that is used by Merge if no
+// codec is given. Codecs should only be modified during initialization.
+var Codecs = map[string]Codec{
+	".json": JSON,
+	".toml": TOML,
+	".yaml": YAML,
+	".yml":  YAML,
+}
+
+// ErrUnknownCodec is returned by Merge if no codec is given and the
+// extension of the file is not in Codecs.
+var ErrUnknownCodec = errors.New("no codec for file extension")
+
+// Merge decodes every configuration file that xdg.FindAllConfig finds with
+// c and merges them, from the least preferred file to the most preferred
+// one. If c is nil, the codec is chosen from Codecs by the extension of file.
+// If no file is found, an error wrapping fs.ErrNotExist is returned.
+func Merge(file string, c Codec) (map[string]any, error) {
+	return merge(file, xdg.FindAllConfig(file), c)
+}
+
+// MergeDirs is like Merge, but searches the configuration directories of b.
+func MergeDirs(b *xdg.BaseDirs, file string, c Codec) (map[string]any, error) {
+	return merge(file, b.FindAllConfig(file), c)
+}
+
+// MergeJSON is the same as Merge(file, JSON).
+func MergeJSON(file string) (map[string]any, error) { return Merge(file, JSON) }
+
+// MergeTOML is the same as Merge(file, TOML).
+func MergeTOML(file string) (map[string]any, error) { return Merge(file, TOML) }
+
+// MergeYAML is the same as Merge(file, YAML).
+func MergeYAML(file string) (map[string]any, error) { return Merge(file, YAML) }
+
+func merge(file string, paths []string, c Codec) (map[string]any, error) {
+	if c == nil {
+		c = Codecs[filepath.Ext(file)]
+		if c == nil {
+			return nil, &fs.PathError{Op: "merge", Path: file, Err: ErrUnknownCodec}
+		}
+	}
+	if len(paths) == 0 {
+		return nil, &fs.PathError{Op: "merge", Path: file, Err: fs.ErrNotExist}
+	}
+	m := make(map[string]any)
+	for i := len(paths) - 1; i >= 0; i-- {
+		v, err := decodeFile(paths[i], c)
+		if err != nil {
+			return nil, err
+		}
+		Deep(m, v)
+	}
+	return m, nil
+}
+
+func decodeFile(path string, c Codec) (map[string]any, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	m, err := c(f)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	return m, nil
+}
+
+// Deep merges src into dst recursively: if a key refers to a map in both
+// dst and src, the maps are merged, otherwise the value in src replaces
+// the value in dst. Maps in src are copied rather than shared with dst.
+func Deep(dst, src map[string]any) {
+	for k, sv := range src {
+		sm, ok := sv.(map[string]any)
+		if !ok {
+			dst[k] = sv
+			continue
+		}
+		dm, ok := dst[k].(map[string]any)
+		if !ok {
+			dm = make(map[string]any, len(sm))
+			dst[k] = dm
+		}
+		Deep(dm, sm)
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"io"
+	"io/fs"
+	"os"
+)
+
+// OpenMerged returns a reader that reads all files of category that FindAll
+// finds, one after the other, as if they were a single file. If rev is
+// true, the files are read in reverse order, i.e. the least preferred file
+// first, which is usually what is wanted if later values override earlier
+// ones. If a file does not end with a newline, one is inserted after it, so
+// that line-oriented formats can be parsed in one pass.
+//
+// The files are opened one at a time, as they are reached. Close closes the
+// file that is currently open; it must be called even if reading failed.
+// If no file is found, an error wrapping fs.ErrNotExist is returned.
+func (b *BaseDirs) OpenMerged(category, file string, rev bool) (io.ReadCloser, error) {
+	ps := b.FindAll(category, file)
+	if len(ps) == 0 {
+		return nil, &fs.PathError{Op: "open", Path: file, Err: fs.ErrNotExist}
+	}
+	if rev {
+		ps = reverse(ps)
+	}
+	return &mergedReader{files: ps}, nil
+}
+
+func (b *BaseDirs) OpenMergedConfig(file string, rev bool) (io.ReadCloser, error) {
+	return b.OpenMerged("config", file, rev)
+}
+func (b *BaseDirs) OpenMergedData(file string, rev bool) (io.ReadCloser, error) {
+	return b.OpenMerged("data", file, rev)
+}
+
+func OpenMerged(category, file string, rev bool) (io.ReadCloser, error) {
+	return defaults().OpenMerged(category, file, rev)
+}
+func OpenMergedConfig(file string, rev bool) (io.ReadCloser, error) {
+	return defaults().OpenMergedConfig(file, rev)
+}
+func OpenMergedData(file string, rev bool) (io.ReadCloser, error) {
+	return defaults().OpenMergedData(file, rev)
+}
+
+// mergedReader reads files one after the other.
+type mergedReader struct {
+	files []string
+	cur   *os.File
+	last  byte // last byte read from cur, or 0 if none
+	err   error
+}
+
+func (m *mergedReader) Read(p []byte) (int, error) {
+	for m.err == nil {
+		if m.cur == nil {
+			if len(m.files) == 0 {
+				m.err = io.EOF
+				break
+			}
+			m.cur, m.err = os.Open(m.files[0])
+			m.files = m.files[1:]
+			m.last = 0
+			continue
+		}
+
+		n, err := m.cur.Read(p)
+		if n > 0 {
+			m.last = p[n-1]
+			return n, nil
+		}
+		if err == io.EOF {
+			err = m.cur.Close()
+			m.cur = nil
+			if err == nil && m.last != 0 && m.last != '\n' && len(p) > 0 {
+				p[0] = '\n'
+				return 1, nil
+			}
+		}
+		m.err = err
+	}
+	return 0, m.err
+}
+
+func (m *mergedReader) Close() error {
+	m.files = nil
+	if m.err == nil {
+		m.err = os.ErrClosed
+	}
+	if m.cur == nil {
+		return nil
+	}
+	err := m.cur.Close()
+	m.cur = nil
+	return err
+}
+
+// ReaderFunc is given to the MergeReaders* functions to handle the files
+// that they find. It receives a file opened for reading, which is closed
+// after ReaderFunc returns. Errors are handled as for MergeFunc, so
+// ReaderFunc can return Skip to skip the rest of the files.
+type ReaderFunc func(f *os.File) error
+
+// OnUnreadable is called by the MergeReaders* functions for each file that
+// is found but cannot be opened, e.g. because permission is denied. Such
+// files are skipped. If OnUnreadable is nil, they are skipped silently.
+var OnUnreadable func(path string, err error)
+
+// MergeReaders is like Merge, but opens each file for f, and closes it
+// after f returns. Files that cannot be opened are skipped and reported
+// to OnUnreadable.
+func (b *BaseDirs) MergeReaders(category, file string, f ReaderFunc) error {
+	return b.Merge(category, file, readerMergeFunc(f))
+}
+
+// MergeReadersR is like MergeR, but opens each file for f in the same way
+// as MergeReaders.
+func (b *BaseDirs) MergeReadersR(category, file string, f ReaderFunc) error {
+	return b.MergeR(category, file, readerMergeFunc(f))
+}
+
+func (b *BaseDirs) MergeConfigReaders(file string, f ReaderFunc) error {
+	return b.MergeReaders("config", file, f)
+}
+func (b *BaseDirs) MergeConfigReadersR(file string, f ReaderFunc) error {
+	return b.MergeReadersR("config", file, f)
+}
+func (b *BaseDirs) MergeDataReaders(file string, f ReaderFunc) error {
+	return b.MergeReaders("data", file, f)
+}
+func (b *BaseDirs) MergeDataReadersR(file string, f ReaderFunc) error {
+	return b.MergeReadersR("data", file, f)
+}
+
+func MergeReaders(category, file string, f ReaderFunc) error {
+	return defaults().MergeReaders(category, file, f)
+}
+func MergeReadersR(category, file string, f ReaderFunc) error {
+	return defaults().MergeReadersR(category, file, f)
+}
+func MergeConfigReaders(file string, f ReaderFunc) error {
+	return defaults().MergeConfigReaders(file, f)
+}
+func MergeConfigReadersR(file string, f ReaderFunc) error {
+	return defaults().MergeConfigReadersR(file, f)
+}
+func MergeDataReaders(file string, f ReaderFunc) error {
+	return defaults().MergeDataReaders(file, f)
+}
+func MergeDataReadersR(file string, f ReaderFunc) error {
+	return defaults().MergeDataReadersR(file, f)
+}
+
+// readerMergeFunc returns a MergeFunc that opens each file for f.
+func readerMergeFunc(f ReaderFunc) MergeFunc {
+	return func(path string) error {
+		r, err := os.Open(path)
+		if err != nil {
+			if OnUnreadable != nil {
+				OnUnreadable(path, err)
+			}
+			return nil
+		}
+		defer r.Close()
+		return f(r)
+	}
+}
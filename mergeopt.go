@@ -0,0 +1,232 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// MergeOptions control how MergeOpt merges files. The zero value merges
+// in the same way as Merge, so Merge(category, file, f) is the same as
+// MergeOpt(category, file, f, MergeOptions{}), and MergeR sets Reverse.
+// New options are only added to MergeOptions, not as further variants of
+// Merge.
+type MergeOptions struct {
+	// Reverse passes the files from the least preferred to the most
+	// preferred one, as MergeR does, so that later files can override
+	// values of earlier ones.
+	Reverse bool
+
+	// ContinueOnError makes MergeOpt continue with the remaining files if
+	// the MergeFunc returns an error other than Skip, so that for example
+	// one broken drop-in file does not disable the others. Each error is
+	// wrapped in an *os.PathError with Op "merge" and the path of the file,
+	// and the errors are joined with errors.Join and returned after all
+	// files are merged.
+	ContinueOnError bool
+
+	// Symlinks determines how files that are symbolic links are handled.
+	// Dangling symbolic links are always left out.
+	Symlinks SymlinkPolicy
+
+	// Dedupe leaves out files that resolve to the same file as a file in a
+	// preferred base directory, e.g. because a dotfile manager linked
+	// ~/.config/dromi.conf to /etc/xdg/dromi.conf.
+	Dedupe bool
+
+	// Include and Exclude are patterns with the syntax of path.Match, such
+	// as "*.conf" or ".#*", which are matched against the base name of each
+	// file. If Include is not empty, files that do not match any pattern in
+	// Include are left out. Files that match any pattern in Exclude are left
+	// out. Malformed patterns do not match any file.
+	Include []string
+	Exclude []string
+
+	// Filter, if not nil, is called with the absolute path of each file
+	// that is found, e.g. the MatchString method of a regexp.Regexp. Files
+	// for which it returns false are left out.
+	Filter func(path string) bool
+}
+
+// SymlinkPolicy determines how files that are symbolic links are handled.
+type SymlinkPolicy int
+
+const (
+	// FollowSymlinks treats symbolic links like the files they point to.
+	FollowSymlinks SymlinkPolicy = iota
+
+	// SkipSymlinks leaves out files that are symbolic links.
+	SkipSymlinks
+
+	// ResolveSymlinks replaces the paths of files by the paths that they
+	// resolve to with filepath.EvalSymlinks.
+	ResolveSymlinks
+)
+
+// MergeOpt is like Merge, but merges the files as opts specifies.
+func (b *BaseDirs) MergeOpt(category, file string, f MergeFunc, opts MergeOptions) error {
+	if _, ok := b.category(category); !ok {
+		return ErrUnknownCategory
+	}
+	return mergeOpt(b.FindAll(category, file), f, opts)
+}
+
+func (b *BaseDirs) MergeConfigFilesOpt(file string, f MergeFunc, opts MergeOptions) error {
+	return b.MergeOpt("config", file, f, opts)
+}
+func (b *BaseDirs) MergeDataFilesOpt(file string, f MergeFunc, opts MergeOptions) error {
+	return b.MergeOpt("data", file, f, opts)
+}
+
+func MergeOpt(category, file string, f MergeFunc, opts MergeOptions) error {
+	return defaults().MergeOpt(category, file, f, opts)
+}
+func MergeConfigFilesOpt(file string, f MergeFunc, opts MergeOptions) error {
+	return defaults().MergeConfigFilesOpt(file, f, opts)
+}
+func MergeDataFilesOpt(file string, f MergeFunc, opts MergeOptions) error {
+	return defaults().MergeDataFilesOpt(file, f, opts)
+}
+
+// FindAllOpt is like FindAll, but returns the files that MergeOpt would
+// pass to the MergeFunc, in that order.
+func (b *BaseDirs) FindAllOpt(category, file string, opts MergeOptions) []string {
+	return selectFiles(b.FindAll(category, file), opts)
+}
+
+// FindOpt is like Find, but returns the first file that FindAllOpt returns.
+func (b *BaseDirs) FindOpt(category, file string, opts MergeOptions) string {
+	if ps := b.FindAllOpt(category, file, opts); len(ps) > 0 {
+		return ps[0]
+	}
+	return ""
+}
+
+func FindAllOpt(category, file string, opts MergeOptions) []string {
+	return defaults().FindAllOpt(category, file, opts)
+}
+func FindOpt(category, file string, opts MergeOptions) string {
+	return defaults().FindOpt(category, file, opts)
+}
+
+// MergeDir merges the files in the directory dir of category, such as a
+// conf.d directory of drop-in files, as MergeOpt does. The directory is
+// read in all base directories, and a file in a preferred base directory
+// hides files of the same name in the others, so that a user can override
+// or disable (e.g. with an empty file) a global drop-in file. The files are
+// merged in lexical order of their names; subdirectories are left out.
+//
+// If dir does not exist in any base directory, nil is returned.
+func (b *BaseDirs) MergeDir(category, dir string, f MergeFunc, opts MergeOptions) error {
+	if _, ok := b.category(category); !ok {
+		return ErrUnknownCategory
+	}
+	if !validFile(dir) {
+		return errInvalidFile("merge", dir)
+	}
+	dir = path.Clean(dir)
+	paths := b.Paths(category)
+	es, err := unionFS(paths).ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	files := make([]string, 0, len(es))
+	for _, e := range es {
+		p := find(dir+"/"+e.Name(), paths)
+		if fi, err := os.Stat(p); err == nil && !fi.IsDir() {
+			files = append(files, p)
+		}
+	}
+	return mergeOpt(files, f, opts)
+}
+
+func (b *BaseDirs) MergeConfigDir(dir string, f MergeFunc, opts MergeOptions) error {
+	return b.MergeDir("config", dir, f, opts)
+}
+func (b *BaseDirs) MergeDataDir(dir string, f MergeFunc, opts MergeOptions) error {
+	return b.MergeDir("data", dir, f, opts)
+}
+
+func MergeDir(category, dir string, f MergeFunc, opts MergeOptions) error {
+	return defaults().MergeDir(category, dir, f, opts)
+}
+func MergeConfigDir(dir string, f MergeFunc, opts MergeOptions) error {
+	return defaults().MergeConfigDir(dir, f, opts)
+}
+func MergeDataDir(dir string, f MergeFunc, opts MergeOptions) error {
+	return defaults().MergeDataDir(dir, f, opts)
+}
+
+func mergeOpt(files []string, f MergeFunc, opts MergeOptions) error {
+	var errs []error
+	for _, p := range selectFiles(files, opts) {
+		err := f(p)
+		if errors.Is(err, Skip) {
+			break
+		} else if err != nil {
+			if !opts.ContinueOnError {
+				return err
+			}
+			errs = append(errs, &os.PathError{Op: "merge", Path: p, Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// selectFiles returns the files that are merged according to opts, in the
+// order in which they are merged.
+func selectFiles(files []string, opts MergeOptions) []string {
+	var (
+		fs   = make([]string, 0, len(files))
+		seen = make(map[string]bool)
+	)
+	for _, p := range files {
+		switch opts.Symlinks {
+		case SkipSymlinks:
+			fi, err := os.Lstat(p)
+			if err != nil || fi.Mode()&os.ModeSymlink != 0 {
+				continue
+			}
+		case ResolveSymlinks:
+			r, err := filepath.EvalSymlinks(p)
+			if err != nil {
+				continue
+			}
+			p = r
+		}
+		if opts.Dedupe {
+			r, err := filepath.EvalSymlinks(p)
+			if err != nil || seen[r] {
+				continue
+			}
+			seen[r] = true
+		}
+		name := filepath.Base(p)
+		if len(opts.Include) > 0 && !matchAny(opts.Include, name) || matchAny(opts.Exclude, name) {
+			continue
+		}
+		if opts.Filter != nil && !opts.Filter(p) {
+			continue
+		}
+		fs = append(fs, p)
+	}
+	if opts.Reverse {
+		fs = reverse(fs)
+	}
+	return fs
+}
+
+// matchAny returns true if name matches any of patterns.
+func matchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,9 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+//go:build !linux
+
+package xdg
+
+func probeNotify(dir string) bool { return false }
@@ -0,0 +1,63 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+// Option configures the resolution of a BaseDirs by New. Options take
+// precedence over the package variables that they correspond to, so that
+// a BaseDirs can be configured without affecting the package defaults.
+type Option func(*options)
+
+// options are the settings with which a BaseDirs is resolved.
+type options struct {
+	getenv func(string) string
+	home   string
+	expand bool
+	level  Level
+	app    string
+
+	// environ is set by NewFromEnviron, which does not read the state of
+	// the current user.
+	environ bool
+}
+
+// newOptions returns the options given by the package variables, with
+// getenv to read the environment.
+func newOptions(getenv func(string) string) options {
+	return options{getenv: getenv, expand: Expand, level: Strictness}
+}
+
+// WithGetenv makes New read the environment with getenv instead of Getenv.
+func WithGetenv(getenv func(string) string) Option {
+	return func(o *options) { o.getenv = getenv }
+}
+
+// WithLookupEnv makes New read the environment with lookup, which has the
+// signature of os.LookupEnv, instead of Getenv. A variable that is set to
+// "" is treated as not set, as the specification requires.
+func WithLookupEnv(lookup func(string) (string, bool)) Option {
+	return func(o *options) {
+		o.getenv = func(key string) string {
+			v, _ := lookup(key)
+			return v
+		}
+	}
+}
+
+// WithHome makes New use home as the home directory, instead of $HOME.
+func WithHome(home string) Option { return func(o *options) { o.home = home } }
+
+// WithExpand sets whether New expands values, as Expand does.
+func WithExpand(expand bool) Option { return func(o *options) { o.expand = expand } }
+
+// WithStrictness sets the level of compliance, as Strictness does.
+func WithStrictness(l Level) Option { return func(o *options) { o.level = l } }
+
+// WithAppName records the name of the application in the BaseDirs, so
+// that code that receives it can build the AppDirs with
+// b.App(b.AppName()).
+func WithAppName(name string) Option { return func(o *options) { o.app = name } }
+
+// AppName returns the name of the application set with WithAppName, or "".
+func (b *BaseDirs) AppName() string { return b.app }
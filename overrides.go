@@ -0,0 +1,144 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+// The Set* methods override a base directory of b, after checking that the
+// new value is valid, that is, an absolute path. If it is not, an error
+// wrapping ErrInvalidPath is returned and b is not modified. Otherwise, the
+// errors that resolution recorded for the variable are removed from Errors,
+// and the cache that CacheLookups enables is invalidated.
+
+func (b *BaseDirs) SetConfigHome(dir string) error {
+	return b.setDir(&b.ConfigHome, "XDG_CONFIG_HOME", dir)
+}
+func (b *BaseDirs) SetDataHome(dir string) error {
+	return b.setDir(&b.DataHome, "XDG_DATA_HOME", dir)
+}
+func (b *BaseDirs) SetCacheHome(dir string) error {
+	return b.setDir(&b.CacheHome, "XDG_CACHE_HOME", dir)
+}
+func (b *BaseDirs) SetStateHome(dir string) error {
+	return b.setDir(&b.StateHome, "XDG_STATE_HOME", dir)
+}
+func (b *BaseDirs) SetBinHome(dir string) error {
+	return b.setDir(&b.BinHome, "XDG_BIN_HOME", dir)
+}
+func (b *BaseDirs) SetConfigDirs(dirs []string) error {
+	return b.setDirs(&b.ConfigDirs, "XDG_CONFIG_DIRS", dirs)
+}
+func (b *BaseDirs) SetDataDirs(dirs []string) error {
+	return b.setDirs(&b.DataDirs, "XDG_DATA_DIRS", dirs)
+}
+
+// SetRuntimeDir also sets RuntimeSource to RuntimeOverride, so that the
+// directory is used as it is.
+func (b *BaseDirs) SetRuntimeDir(dir string) error {
+	if err := b.setDir(&b.RuntimeDir, "XDG_RUNTIME_DIR", dir); err != nil {
+		return err
+	}
+	b.RuntimeSource = RuntimeOverride
+	return nil
+}
+
+func (b *BaseDirs) setDir(p *string, env, dir string) error {
+	if err := checkAbs(dir); err != nil {
+		return err
+	}
+	*p = dir
+	b.overridden(env)
+	return nil
+}
+
+func (b *BaseDirs) setDirs(p *[]string, env string, dirs []string) error {
+	if err := checkAbs(dirs...); err != nil {
+		return err
+	}
+	*p = append([]string(nil), dirs...)
+	b.overridden(env)
+	return nil
+}
+
+// overridden updates the state that depends on the variable env after it
+// has been overridden.
+func (b *BaseDirs) overridden(env string) {
+	// b may be a copy that shares Errors with the original, so the slice is
+	// not filtered in place.
+	var errs []error
+	for _, err := range b.Errors {
+		var v *VarError
+		if errors.As(err, &v) && v.Var == env {
+			continue
+		}
+		errs = append(errs, err)
+	}
+	b.Errors = errs
+	InvalidateCache()
+}
+
+// checkAbs returns an error wrapping ErrInvalidPath if one of dirs is not
+// an absolute path.
+func checkAbs(dirs ...string) error {
+	for _, d := range dirs {
+		if !filepath.IsAbs(d) {
+			return fmt.Errorf("%w: %q is not absolute", ErrInvalidPath, d)
+		}
+	}
+	return nil
+}
+
+// The package-level Set* functions override a base directory of the default
+// BaseDirs in the same way, and update the package variables, including
+// ConfigHomeDirs and DataHomeDirs. The default BaseDirs is replaced
+// atomically, as with Reload, which undoes all overrides.
+
+func SetConfigHome(dir string) error {
+	return override(func(b *BaseDirs) error { return b.SetConfigHome(dir) })
+}
+func SetDataHome(dir string) error {
+	return override(func(b *BaseDirs) error { return b.SetDataHome(dir) })
+}
+func SetCacheHome(dir string) error {
+	return override(func(b *BaseDirs) error { return b.SetCacheHome(dir) })
+}
+func SetStateHome(dir string) error {
+	return override(func(b *BaseDirs) error { return b.SetStateHome(dir) })
+}
+func SetBinHome(dir string) error {
+	return override(func(b *BaseDirs) error { return b.SetBinHome(dir) })
+}
+func SetRuntimeDir(dir string) error {
+	return override(func(b *BaseDirs) error { return b.SetRuntimeDir(dir) })
+}
+func SetConfigDirs(dirs []string) error {
+	return override(func(b *BaseDirs) error { return b.SetConfigDirs(dirs) })
+}
+func SetDataDirs(dirs []string) error {
+	return override(func(b *BaseDirs) error { return b.SetDataDirs(dirs) })
+}
+
+// override applies f to a copy of the default BaseDirs, and makes the copy
+// the new default if f succeeds. UserDirs is updated afterwards, since the
+// user directories are read from ConfigHome and ConfigDirs.
+func override(f func(b *BaseDirs) error) error {
+	once.Do(func() { load() })
+	mu.Lock()
+	b := *std
+	err := f(&b)
+	if err == nil {
+		setDefault(&b)
+	}
+	mu.Unlock()
+	if err != nil {
+		return err
+	}
+	setUserDirs(&b)
+	return nil
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrRunning is returned by WritePIDFile if the PID file belongs to a
+// process that is still running.
+var ErrRunning = errors.New("process is already running")
+
+// WritePIDFile writes the PID of the current process to the file name in
+// RuntimeDir, e.g. "dromi/dromi.pid". The file is created atomically, so
+// that two processes cannot both succeed. If the file already exists and
+// the process it names is still running, the error wraps ErrRunning;
+// if that process is gone, the stale file is replaced. Processes replace a
+// stale file one at a time, holding a lock on the file with the additional
+// extension ".lock", which is left in place. On systems without flock(2),
+// see Lock, two processes that replace the same stale file at the same
+// time may both succeed.
+//
+// The returned function removes the PID file, if it still contains the PID
+// of the current process; it should be called on exit, and may be called
+// more than once. CleanupRuntime calls it as well.
+func (b *BaseDirs) WritePIDFile(name string) (release func(), err error) {
+	p, err := b.runtimePath("write", name)
+	if err != nil {
+		return nil, err
+	}
+	pid := os.Getpid()
+	err = b.createPIDFile(p, pid)
+	if os.IsExist(err) {
+		err = b.replacePIDFile(p, pid)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var once sync.Once
+	release = func() {
+		once.Do(func() {
+			UntrackRuntimeFile(p)
+			if other, err := readPIDFile(p); err == nil && other == pid {
+				os.Remove(p)
+			}
+		})
+	}
+	track(p, func() error { release(); return nil })
+	return release, nil
+}
+
+// ReadPIDFile returns the PID in the file name in RuntimeDir, as written by
+// WritePIDFile, and whether that process is still running.
+func (b *BaseDirs) ReadPIDFile(name string) (pid int, running bool, err error) {
+	if b.RuntimeDir == "" {
+		return 0, false, errUnresolved("XDG_RUNTIME_DIR")
+	}
+	p := join(b.RuntimeDir, name)
+	if p == "" {
+		return 0, false, errInvalidFile("read", name)
+	}
+	pid, err = readPIDFile(p)
+	if err != nil {
+		return 0, false, err
+	}
+	return pid, processAlive(pid), nil
+}
+
+func WritePIDFile(name string) (release func(), err error) { return defaults().WritePIDFile(name) }
+func ReadPIDFile(name string) (pid int, running bool, err error) {
+	return defaults().ReadPIDFile(name)
+}
+
+// replacePIDFile replaces the existing PID file p with one that contains
+// pid, unless the process that p names is still running. The lock on the
+// file p+".lock" ensures that the PID file that is read is the one that is
+// removed, and not one that another process has just created instead.
+func (b *BaseDirs) replacePIDFile(p string, pid int) error {
+	l, err := b.openCreate(p + ".lock")
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+	if err := flock(l, true); err == nil {
+		defer funlock(l)
+	} else if !errors.Is(err, errors.ErrUnsupported) {
+		return err
+	}
+
+	other, err := readPIDFile(p)
+	if err == nil && other != pid && processAlive(other) {
+		return &os.PathError{Op: "write", Path: p, Err: fmt.Errorf("%w (pid %d)", ErrRunning, other)}
+	}
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return b.createPIDFile(p, pid)
+}
+
+// createPIDFile creates p with pid as content, or fails if p exists. The
+// content is written to a temporary file first, which is then linked to p,
+// so that readers never see an empty PID file.
+func (b *BaseDirs) createPIDFile(p string, pid int) error {
+	f, err := b.newAtomicFile(p, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Abort()
+	if _, err := f.Write([]byte(strconv.Itoa(pid) + "\n")); err != nil {
+		return err
+	}
+	if err := f.tmp.Sync(); err != nil {
+		return err
+	}
+	return os.Link(f.tmp.Name(), p)
+}
+
+func readPIDFile(p string) (int, error) {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, &os.PathError{Op: "read", Path: p, Err: errors.New("invalid PID file")}
+	}
+	return pid, nil
+}
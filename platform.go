@@ -0,0 +1,65 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Native makes the package use the native directories of the platform as
+// defaults instead of those of the specification, where they differ. This
+// is for GUI applications, which are expected to follow the conventions of
+// the platform, while command-line tools usually prefer the XDG layout.
+// Environment variables that are set are honored in either case.
+//
+// Currently, Native only has an effect on macOS, where it selects
+// ~/Library/Application Support, ~/Library/Caches, and the per-user
+// temporary directory; on Windows, the Known Folders are always used.
+// If you change Native after the package has been initialized, you need to
+// call Init() again.
+var Native = false
+
+// defaultDirs contains the defaults of the base directories that are used
+// when the corresponding environment variables are not set. An occurrence
+// of "$HOME" is replaced by the home directory; configDirs and dataDirs are
+// lists separated by os.PathListSeparator.
+type defaultDirs struct {
+	configHome string
+	dataHome   string
+	cacheHome  string
+	stateHome  string
+	binHome    string
+	runtimeDir string
+	configDirs string
+	dataDirs   string
+}
+
+// tempDir returns the directory for temporary files, on which fallback
+// directories are based: $TMPDIR, as read by getenv, if it is absolute, as
+// on macOS or on HPC systems that point it at fast local storage, or else
+// the default of the system, e.g. /tmp. Unlike os.TempDir, it does not
+// read the process environment.
+func tempDir(getenv func(string) string) string {
+	if dir := getenv("TMPDIR"); filepath.IsAbs(dir) {
+		return dir
+	}
+	return systemTempDir(getenv)
+}
+
+// specDefaults returns the defaults of the specification.
+func specDefaults(getenv func(string) string) defaultDirs {
+	return defaultDirs{
+		configHome: "$HOME/.config",
+		dataHome:   "$HOME/.local/share",
+		cacheHome:  "$HOME/.cache",
+		stateHome:  "$HOME/.local/state",
+		binHome:    "$HOME/.local/bin",
+		runtimeDir: filepath.Join(tempDir(getenv), fmt.Sprintf("xdg-%d", os.Getuid())),
+		configDirs: "/etc/xdg",
+		dataDirs:   "/usr/local/share:/usr/share",
+	}
+}
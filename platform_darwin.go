@@ -0,0 +1,28 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+// homeDir returns the home directory of the user, as read by getenv.
+func homeDir(getenv func(string) string) (string, HomeSource) { return getenv("HOME"), HomeEnv }
+
+// platformDefaults returns the defaults of the specification, or the
+// directories of macOS if Native is set. The runtime directory is then the
+// per-user temporary directory, confstr(_CS_DARWIN_USER_TEMP_DIR), which
+// launchd passes to every process as $TMPDIR.
+func platformDefaults(getenv func(string) string) defaultDirs {
+	if !Native {
+		return specDefaults(getenv)
+	}
+	return defaultDirs{
+		configHome: "$HOME/Library/Application Support",
+		dataHome:   "$HOME/Library/Application Support",
+		cacheHome:  "$HOME/Library/Caches",
+		stateHome:  "$HOME/Library/Application Support",
+		binHome:    "$HOME/.local/bin",
+		runtimeDir: tempDir(getenv),
+		configDirs: "/Library/Application Support",
+		dataDirs:   "/Library/Application Support",
+	}
+}
@@ -0,0 +1,12 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+//go:build !darwin && !js && !wasip1 && !windows
+
+package xdg
+
+// homeDir returns the home directory of the user, as read by getenv.
+func homeDir(getenv func(string) string) (string, HomeSource) { return getenv("HOME"), HomeEnv }
+
+func platformDefaults(getenv func(string) string) defaultDirs { return specDefaults(getenv) }
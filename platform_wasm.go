@@ -0,0 +1,26 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+//go:build js || wasip1
+
+package xdg
+
+import (
+	"path/filepath"
+)
+
+// homeDir returns the home directory of the user, as read by getenv. A
+// WebAssembly host often passes no $HOME, in which case a directory in the
+// temporary directory stands in for it, so that the base directories
+// resolve. Only the paths are provided; whether files can actually be
+// written there depends on the host: Node.js provides its file system, a
+// WASI runtime its preopened directories, and a browser none.
+func homeDir(getenv func(string) string) (string, HomeSource) {
+	if home := getenv("HOME"); home != "" {
+		return home, HomeEnv
+	}
+	return filepath.Join(tempDir(getenv), "xdg-home"), HomeTemp
+}
+
+func platformDefaults(getenv func(string) string) defaultDirs { return specDefaults(getenv) }
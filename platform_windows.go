@@ -0,0 +1,57 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"path/filepath"
+)
+
+// homeDir returns the home directory of the user, as read by getenv. $HOME
+// is preferred, since MSYS and Cygwin set it, but usually only
+// %USERPROFILE% is set.
+func homeDir(getenv func(string) string) (string, HomeSource) {
+	if home := getenv("HOME"); home != "" {
+		return home, HomeEnv
+	}
+	return getenv("USERPROFILE"), HomeProfile
+}
+
+// systemTempDir returns the temporary directory of the user, as
+// GetTempPath determines it, but reading the environment with getenv.
+func systemTempDir(getenv func(string) string) string {
+	for _, env := range []string{"TMP", "TEMP", "USERPROFILE"} {
+		if dir := getenv(env); filepath.IsAbs(dir) {
+			return dir
+		}
+	}
+	return `C:\Windows`
+}
+
+// platformDefaults returns the Known Folders of Windows, as found in the
+// environment: configuration and data files go to %APPDATA%, which roams
+// with the user, cache and state files to %LOCALAPPDATA%, which does not,
+// and the global directories are %PROGRAMDATA%. Variables that are not set
+// are replaced by their usual location in the home directory.
+func platformDefaults(getenv func(string) string) defaultDirs {
+	folder := func(env, def string) string {
+		if dir := getenv(env); dir != "" {
+			return dir
+		}
+		return def
+	}
+	appData := folder("APPDATA", `$HOME\AppData\Roaming`)
+	localAppData := folder("LOCALAPPDATA", `$HOME\AppData\Local`)
+	programData := folder("PROGRAMDATA", `C:\ProgramData`)
+	return defaultDirs{
+		configHome: appData,
+		dataHome:   appData,
+		cacheHome:  filepath.Join(localAppData, "cache"),
+		stateHome:  localAppData,
+		binHome:    filepath.Join(localAppData, "Programs"),
+		runtimeDir: filepath.Join(tempDir(getenv), "xdg"),
+		configDirs: programData,
+		dataDirs:   programData,
+	}
+}
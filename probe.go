@@ -0,0 +1,57 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+)
+
+// Capabilities reports which of the features that the specification
+// requires of the runtime directory are available. A feature that could not
+// be tested on the current system is reported as unavailable.
+type Capabilities struct {
+	UnixSockets bool // Unix domain sockets can be created
+	HardLinks   bool // hard links can be created
+	Symlinks    bool // symbolic links can be created
+	Locking     bool // files can be locked with flock(2)
+	Mmap        bool // files can be memory mapped
+	Notify      bool // changes can be watched, e.g. with inotify(7)
+}
+
+// ProbeRuntimeDir tests which features RuntimeDir supports, by trying each
+// of them in a temporary directory in RuntimeDir, which is removed again.
+// This is useful if RuntimeSource is RuntimeFallback, since the replacement
+// directory may lack some of them. An error is only returned if the tests
+// cannot be performed at all.
+func (b *BaseDirs) ProbeRuntimeDir() (Capabilities, error) {
+	var c Capabilities
+	dir, err := b.MkdirRuntimeTemp(".xdg-probe-*")
+	if err != nil {
+		return c, err
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, []byte("xdg\n"), 0600); err != nil {
+		return c, err
+	}
+	if l, err := net.Listen("unix", filepath.Join(dir, "socket")); err == nil {
+		c.UnixSockets = true
+		l.Close()
+	}
+	c.HardLinks = os.Link(file, filepath.Join(dir, "link")) == nil
+	c.Symlinks = os.Symlink("file", filepath.Join(dir, "symlink")) == nil
+	if f, err := os.OpenFile(file, os.O_RDWR, 0); err == nil {
+		c.Locking = flock(f, false) == nil
+		c.Mmap = probeMmap(f)
+		f.Close()
+	}
+	c.Notify = probeNotify(dir)
+	return c, nil
+}
+
+func ProbeRuntimeDir() (Capabilities, error) { return defaults().ProbeRuntimeDir() }
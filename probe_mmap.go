@@ -0,0 +1,22 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package xdg
+
+import (
+	"os"
+	"syscall"
+)
+
+// probeMmap returns true if the first page of f can be memory mapped.
+func probeMmap(f *os.File) bool {
+	b, err := syscall.Mmap(int(f.Fd()), 0, 4, syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return false
+	}
+	syscall.Munmap(b)
+	return true
+}
@@ -0,0 +1,11 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package xdg
+
+import "os"
+
+func probeMmap(f *os.File) bool { return false }
@@ -0,0 +1,73 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Promote returns the path of file in the user base directory of category,
+// so that it can be edited. If the file does not exist there yet, but in a
+// global base directory, such as /etc/xdg, the file that Find returns is
+// copied to the user base directory first, with its permissions. This is
+// how a command that edits the effective configuration should proceed.
+//
+// If file does not exist in any base directory, the error wraps
+// fs.ErrNotExist.
+func (b *BaseDirs) Promote(category, file string) (string, error) {
+	d, ok := b.category(category)
+	if !ok {
+		return "", ErrUnknownCategory
+	}
+	if d.home == "" {
+		return "", errUnresolved(d.env)
+	}
+	dst := join(d.home, file)
+	if dst == "" {
+		return "", errInvalidFile("promote", file)
+	}
+	if _, err := os.Stat(dst); err == nil {
+		return dst, nil
+	}
+	src := find(file, d.dirs)
+	if src == "" {
+		return "", &fs.PathError{Op: "promote", Path: file, Err: fs.ErrNotExist}
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+	fi, err := in.Stat()
+	if err != nil {
+		return "", err
+	}
+	if err := b.mkdirAll(filepath.Dir(dst), dirPerm(category)); err != nil {
+		return "", err
+	}
+	out, err := b.newAtomicFile(dst, fi.Mode().Perm())
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Abort()
+		return "", err
+	}
+	if err := out.Close(); err != nil {
+		return "", err
+	}
+	return dst, nil
+}
+
+func (b *BaseDirs) PromoteConfig(file string) (string, error) { return b.Promote("config", file) }
+func (b *BaseDirs) PromoteData(file string) (string, error)   { return b.Promote("data", file) }
+
+func Promote(category, file string) (string, error) { return defaults().Promote(category, file) }
+func PromoteConfig(file string) (string, error)     { return defaults().PromoteConfig(file) }
+func PromoteData(file string) (string, error)       { return defaults().PromoteData(file) }
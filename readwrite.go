@@ -0,0 +1,105 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ReadFile reads the file of category that Find returns, i.e. the file in
+// the most preferred base directory. If the file does not exist in any base
+// directory, the error wraps fs.ErrNotExist.
+func (b *BaseDirs) ReadFile(category, file string) ([]byte, error) {
+	if _, ok := b.category(category); !ok {
+		return nil, ErrUnknownCategory
+	}
+	if !validFile(file) {
+		return nil, errInvalidFile("read", file)
+	}
+	p := b.Find(category, file)
+	if p == "" {
+		return nil, &fs.PathError{Op: "read", Path: file, Err: fs.ErrNotExist}
+	}
+	return os.ReadFile(p)
+}
+
+// WriteFile writes data to file in the user base directory of category,
+// creating the directories leading to it if necessary. The data is written
+// to a temporary file in the same directory, which is then renamed to file,
+// so that readers never see a partially written file. If file does not
+// exist, it is created with perm; otherwise it is replaced, and also gets
+// the permissions perm.
+func (b *BaseDirs) WriteFile(category, file string, data []byte, perm os.FileMode) error {
+	d, ok := b.category(category)
+	if !ok {
+		return ErrUnknownCategory
+	}
+	if d.home == "" {
+		return errUnresolved(d.env)
+	}
+	p := join(d.home, file)
+	if p == "" {
+		return errInvalidFile("write", file)
+	}
+	if err := b.mkdirAll(filepath.Dir(p), dirPerm(category)); err != nil {
+		return err
+	}
+	return b.writeFile(p, data, perm)
+}
+
+// writeFile writes data to the file p atomically, with an AtomicFile.
+func (b *BaseDirs) writeFile(p string, data []byte, perm os.FileMode) error {
+	f, err := b.newAtomicFile(p, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Abort()
+		return err
+	}
+	return f.Close()
+}
+
+func (b *BaseDirs) ReadConfigFile(file string) ([]byte, error) { return b.ReadFile("config", file) }
+func (b *BaseDirs) ReadDataFile(file string) ([]byte, error)   { return b.ReadFile("data", file) }
+func (b *BaseDirs) ReadCacheFile(file string) ([]byte, error)  { return b.ReadFile("cache", file) }
+func (b *BaseDirs) ReadStateFile(file string) ([]byte, error)  { return b.ReadFile("state", file) }
+
+func (b *BaseDirs) WriteConfigFile(file string, data []byte, perm os.FileMode) error {
+	return b.WriteFile("config", file, data, perm)
+}
+func (b *BaseDirs) WriteDataFile(file string, data []byte, perm os.FileMode) error {
+	return b.WriteFile("data", file, data, perm)
+}
+func (b *BaseDirs) WriteCacheFile(file string, data []byte, perm os.FileMode) error {
+	return b.WriteFile("cache", file, data, perm)
+}
+func (b *BaseDirs) WriteStateFile(file string, data []byte, perm os.FileMode) error {
+	return b.WriteFile("state", file, data, perm)
+}
+
+func ReadFile(category, file string) ([]byte, error) { return defaults().ReadFile(category, file) }
+func ReadConfigFile(file string) ([]byte, error)     { return defaults().ReadConfigFile(file) }
+func ReadDataFile(file string) ([]byte, error)       { return defaults().ReadDataFile(file) }
+func ReadCacheFile(file string) ([]byte, error)      { return defaults().ReadCacheFile(file) }
+func ReadStateFile(file string) ([]byte, error)      { return defaults().ReadStateFile(file) }
+
+func WriteFile(category, file string, data []byte, perm os.FileMode) error {
+	return defaults().WriteFile(category, file, data, perm)
+}
+func WriteConfigFile(file string, data []byte, perm os.FileMode) error {
+	return defaults().WriteConfigFile(file, data, perm)
+}
+func WriteDataFile(file string, data []byte, perm os.FileMode) error {
+	return defaults().WriteDataFile(file, data, perm)
+}
+func WriteCacheFile(file string, data []byte, perm os.FileMode) error {
+	return defaults().WriteCacheFile(file, data, perm)
+}
+func WriteStateFile(file string, data []byte, perm os.FileMode) error {
+	return defaults().WriteStateFile(file, data, perm)
+}
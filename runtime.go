@@ -0,0 +1,231 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// RuntimeSource describes how the runtime directory was determined.
+type RuntimeSource int
+
+const (
+	// RuntimeEnv means that RuntimeDir was read from $XDG_RUNTIME_DIR.
+	RuntimeEnv RuntimeSource = iota
+
+	// RuntimeFallback means that $XDG_RUNTIME_DIR was not set, and that
+	// RuntimeDir is a replacement directory in os.TempDir, which is created
+	// with mode 0700 when it is first used. It is not guaranteed to have the
+	// properties that the specification requires, such as being removed
+	// when the user logs out.
+	RuntimeFallback
+
+	// RuntimeSystemd means that $XDG_RUNTIME_DIR was not set, and that
+	// RuntimeDir is /run/user/$UID, which systemd-logind creates for each
+	// user who is logged in. See DetectRuntimeDir.
+	RuntimeSystemd
+
+	// RuntimeService means that $XDG_RUNTIME_DIR was not set, and that
+	// RuntimeDir is /run, because the directories were resolved for a
+	// system service. See Service.
+	RuntimeService
+
+	// RuntimeOverride means that RuntimeDir was set with SetRuntimeDir.
+	// It is used as it is, and its mode is not changed.
+	RuntimeOverride
+)
+
+func (s RuntimeSource) String() string {
+	switch s {
+	case RuntimeEnv:
+		return "XDG_RUNTIME_DIR"
+	case RuntimeFallback:
+		return "fallback"
+	case RuntimeSystemd:
+		return "systemd"
+	case RuntimeService:
+		return "service"
+	case RuntimeOverride:
+		return "override"
+	}
+	return "unknown"
+}
+
+// DetectRuntimeDir enables an additional resolution step for the runtime
+// directory: if $XDG_RUNTIME_DIR is not set, which is common in cron jobs
+// and non-login ssh sessions, but /run/user/$UID exists, belongs to the
+// user, and has mode 0700, that directory is used before falling back to a
+// replacement directory. BaseDirs.RuntimeSource reports which was chosen.
+// If you change DetectRuntimeDir after the package has been initialized, you
+// need to call Init() again.
+var DetectRuntimeDir = false
+
+// systemdRuntimeDir returns /run/user/$UID if it can be used as runtime
+// directory, else "".
+func systemdRuntimeDir() string {
+	uid := os.Getuid()
+	if uid < 0 {
+		return ""
+	}
+	p := fmt.Sprintf("/run/user/%d", uid)
+	fi, err := os.Stat(p)
+	if err != nil || !fi.IsDir() || fi.Mode().Perm() != 0700 {
+		return ""
+	}
+	if owner, ok := fileUID(fi); !ok || owner != uid {
+		return ""
+	}
+	return p
+}
+
+// OnRuntimeFallback is called with the replacement directory whenever the
+// base directories are resolved and $XDG_RUNTIME_DIR is not set, so that
+// the application can print a warning, as the specification recommends.
+// It must not call functions of this package. If OnRuntimeFallback is nil,
+// the replacement directory is used silently.
+var OnRuntimeFallback func(dir string)
+
+var (
+	// ErrRuntimeNotDir is reported by ValidateRuntimeDir if RuntimeDir is
+	// not a directory.
+	ErrRuntimeNotDir = errors.New("not a directory")
+
+	// ErrRuntimeOwner is reported by ValidateRuntimeDir if RuntimeDir is
+	// not owned by the current user.
+	ErrRuntimeOwner = errors.New("not owned by the current user")
+
+	// ErrRuntimeMode is reported by ValidateRuntimeDir if the mode of
+	// RuntimeDir is not 0700.
+	ErrRuntimeMode = errors.New("mode is not 0700")
+
+	// ErrRuntimeRemote is reported by ValidateRuntimeDir if RuntimeDir is
+	// on a network file system.
+	ErrRuntimeRemote = errors.New("on a network file system")
+
+	// ErrRuntimeFS is reported by ValidateRuntimeDir if RuntimeDir is on a
+	// FUSE or overlay file system, which may not support locking, sockets,
+	// or the other file objects that the specification requires.
+	ErrRuntimeFS = errors.New("on a file system with limited support for file objects")
+)
+
+// RuntimeDirError is returned by ValidateRuntimeDir if the runtime directory
+// violates the requirements of the specification. It unwraps to each of the
+// violations, so errors.Is(err, ErrRuntimeMode) reports whether the mode is
+// wrong.
+type RuntimeDirError struct {
+	Dir    string
+	FSType string // type of the file system of Dir, as by FileSystemType
+	Errs   []error
+}
+
+func (e *RuntimeDirError) Error() string {
+	s := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		s[i] = err.Error()
+	}
+	return "invalid XDG runtime directory " + e.Dir + ": " + strings.Join(s, ", ")
+}
+
+func (e *RuntimeDirError) Unwrap() []error { return e.Errs }
+
+// ValidateRuntimeDir checks that RuntimeDir has the properties that the
+// specification requires: it exists, is owned by the current user, and has
+// mode 0700. Where it can be detected, it also checks that the directory is
+// on a local file system that supports all file objects, i.e. not on a
+// network, FUSE, or overlay file system. Violations are returned in a
+// *RuntimeDirError.
+//
+// Checks that are not meaningful on the current system, such as ownership
+// on Windows, are skipped.
+func (b *BaseDirs) ValidateRuntimeDir() error {
+	if b.RuntimeDir == "" {
+		return errUnresolved("XDG_RUNTIME_DIR")
+	}
+	fi, err := os.Stat(b.RuntimeDir)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	if !fi.IsDir() {
+		errs = append(errs, ErrRuntimeNotDir)
+	}
+	if uid, ok := fileUID(fi); ok && uid != os.Getuid() {
+		errs = append(errs, ErrRuntimeOwner)
+	}
+	if runtime.GOOS != "windows" && fi.Mode().Perm() != 0700 {
+		errs = append(errs, ErrRuntimeMode)
+	}
+	fs := FileSystemType(b.RuntimeDir)
+	switch fs {
+	case "nfs", "smb", "cifs", "smb2", "afs", "coda", "ncp", "webdav", "afpfs":
+		errs = append(errs, ErrRuntimeRemote)
+	case "fuse", "overlay":
+		errs = append(errs, ErrRuntimeFS)
+	}
+	if errs != nil {
+		return &RuntimeDirError{Dir: b.RuntimeDir, FSType: fs, Errs: errs}
+	}
+	return nil
+}
+
+// FileSystemType returns the type of the file system that dir is on, such
+// as "ext4", "tmpfs", "nfs", "fuse", or "overlay", or "" if it cannot be
+// detected on the current system. On Linux, file systems that are not
+// known to the package are reported as "other". Programs can use it to
+// choose a different means of communication if the runtime directory is
+// not suitable for sockets or locks.
+func FileSystemType(dir string) string {
+	name, _ := fsType(dir)
+	return name
+}
+
+func ValidateRuntimeDir() error { return defaults().ValidateRuntimeDir() }
+
+// runtimePath prepares RuntimeDir and returns the path of file in it, after
+// creating the directories leading to it with mode 0700.
+func (b *BaseDirs) runtimePath(op, file string) (string, error) {
+	if err := b.prepareRuntimeDir(); err != nil {
+		return "", err
+	}
+	p := join(b.RuntimeDir, file)
+	if p == "" {
+		return "", errInvalidFile(op, file)
+	}
+	if err := b.mkdirAll(filepath.Dir(p), 0700); err != nil {
+		return "", err
+	}
+	return p, nil
+}
+
+// CreateRuntimeTemp is like os.CreateTemp, but creates the file in RuntimeDir,
+// where only the user can access it, rather than in the shared os.TempDir.
+// The file has mode 0600. It is the caller's responsibility to remove it.
+func (b *BaseDirs) CreateRuntimeTemp(pattern string) (*os.File, error) {
+	if err := b.prepareRuntimeDir(); err != nil {
+		return nil, err
+	}
+	return b.createTemp(b.RuntimeDir, pattern)
+}
+
+// MkdirRuntimeTemp is like os.MkdirTemp, but creates the directory in
+// RuntimeDir. The directory has mode 0700. It is the caller's responsibility
+// to remove it.
+func (b *BaseDirs) MkdirRuntimeTemp(pattern string) (string, error) {
+	if err := b.prepareRuntimeDir(); err != nil {
+		return "", err
+	}
+	return b.mkdirTemp(b.RuntimeDir, pattern)
+}
+
+func CreateRuntimeTemp(pattern string) (*os.File, error) {
+	return defaults().CreateRuntimeTemp(pattern)
+}
+func MkdirRuntimeTemp(pattern string) (string, error) { return defaults().MkdirRuntimeTemp(pattern) }
@@ -0,0 +1,151 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// SandboxKind is the kind of application sandbox that the process runs in.
+type SandboxKind int
+
+const (
+	// NoSandbox means that the process does not run in a known sandbox.
+	NoSandbox SandboxKind = iota
+
+	// Flatpak means that the process runs in a Flatpak sandbox, which is
+	// recognized by the file /.flatpak-info.
+	Flatpak
+
+	// Snap means that the process runs in a snap, which is recognized by
+	// the variables $SNAP and $SNAP_USER_DATA.
+	Snap
+)
+
+func (k SandboxKind) String() string {
+	switch k {
+	case NoSandbox:
+		return "none"
+	case Flatpak:
+		return "flatpak"
+	case Snap:
+		return "snap"
+	}
+	return "unknown"
+}
+
+// SandboxInfo describes the application sandbox that the process runs in,
+// and how it affects the base directories.
+type SandboxInfo struct {
+	Kind SandboxKind
+
+	// AppID is the ID of the sandboxed application, e.g. org.gnome.Maps,
+	// or the instance name of a snap.
+	AppID string
+
+	// Home is the private directory of the application in the home
+	// directory of the user, e.g. ~/.var/app/org.gnome.Maps, in which the
+	// sandbox keeps its user base directories, or "" if there is none. For
+	// a snap, this is $SNAP_USER_DATA, e.g. ~/snap/hello/27, which is
+	// specific to the revision of the snap.
+	Home string
+
+	// Common is the private directory of a snap that is shared by all its
+	// revisions, $SNAP_USER_COMMON, e.g. ~/snap/hello/common.
+	Common string
+}
+
+// flatpakInfo is the file that Flatpak places in the root of a sandbox.
+var flatpakInfo = "/.flatpak-info"
+
+// Sandbox returns the application sandbox that the process runs in.
+//
+// Inside a Flatpak sandbox, the XDG variables are normally set by Flatpak.
+// If they are not, the defaults are adjusted to match: the user base
+// directories are in ~/.var/app/<id>, e.g. ~/.var/app/<id>/config, DataDirs
+// starts with /app/share and includes the data directories that the host
+// exports to the sandbox, and ConfigDirs starts with /app/etc/xdg.
+//
+// Inside a snap, AppArmor denies writing to the usual user base
+// directories, so unless the XDG variables are set, ConfigHome, DataHome,
+// and StateHome are in $SNAP_USER_DATA, e.g. $SNAP_USER_DATA/.config, and
+// CacheHome is $SNAP_USER_COMMON/.cache, so that the cache survives updates
+// of the snap.
+func Sandbox() SandboxInfo { return defaults().Sandbox }
+
+// detectSandbox returns the sandbox that the process runs in, reading the
+// environment with getenv.
+func detectSandbox(getenv func(string) string, home string) SandboxInfo {
+	if id, ok := flatpakID(getenv); ok {
+		s := SandboxInfo{Kind: Flatpak, AppID: id}
+		if id != "" && home != "" {
+			s.Home = home + "/.var/app/" + id
+		}
+		return s
+	}
+	if getenv("SNAP") != "" && getenv("SNAP_USER_DATA") != "" {
+		id := getenv("SNAP_INSTANCE_NAME")
+		if id == "" {
+			id = getenv("SNAP_NAME")
+		}
+		return SandboxInfo{
+			Kind:   Snap,
+			AppID:  id,
+			Home:   getenv("SNAP_USER_DATA"),
+			Common: getenv("SNAP_USER_COMMON"),
+		}
+	}
+	return SandboxInfo{}
+}
+
+// flatpakID returns the application ID of the Flatpak sandbox, which is
+// read from $FLATPAK_ID or from the [Application] group of /.flatpak-info,
+// and whether the process runs in a Flatpak sandbox at all.
+func flatpakID(getenv func(string) string) (string, bool) {
+	f, err := os.Open(flatpakInfo)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+	if id := getenv("FLATPAK_ID"); id != "" {
+		return id, true
+	}
+	var group string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if strings.HasPrefix(line, "[") {
+			group = line
+		} else if k, v, ok := strings.Cut(line, "="); ok && group == "[Application]" && strings.TrimSpace(k) == "name" {
+			return strings.TrimSpace(v), true
+		}
+	}
+	return "", true
+}
+
+// adjust changes the defaults of the base directories for the sandbox.
+func (s SandboxInfo) adjust(def *defaultDirs) {
+	switch s.Kind {
+	case Flatpak:
+		if s.Home != "" {
+			def.configHome = s.Home + "/config"
+			def.dataHome = s.Home + "/data"
+			def.cacheHome = s.Home + "/cache"
+			def.stateHome = s.Home + "/.local/state"
+		}
+		def.configDirs = "/app/etc/xdg:" + def.configDirs
+		def.dataDirs = "/app/share:" + def.dataDirs + ":/run/host/user-share:/run/host/share"
+	case Snap:
+		def.configHome = s.Home + "/.config"
+		def.dataHome = s.Home + "/.local/share"
+		def.stateHome = s.Home + "/.local/state"
+		def.cacheHome = s.Home + "/.cache"
+		if s.Common != "" {
+			def.cacheHome = s.Common + "/.cache"
+		}
+	}
+}
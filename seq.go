@@ -0,0 +1,51 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"errors"
+	"io/fs"
+	"iter"
+	"os"
+)
+
+// FilesSeq returns an iterator over the files of category that FindAll
+// would return, in the same order. The base directories are only probed
+// as the iteration proceeds, so callers that stop at the first usable file
+// do not stat the remaining directories.
+//
+// Files that do not exist are skipped; other errors, such as permission
+// errors, are yielded together with the path that caused them.
+func (b *BaseDirs) FilesSeq(category, file string) iter.Seq2[string, error] {
+	return filesSeq(file, b.Paths(category))
+}
+
+func (b *BaseDirs) ConfigFilesSeq(file string) iter.Seq2[string, error] {
+	return b.FilesSeq("config", file)
+}
+func (b *BaseDirs) DataFilesSeq(file string) iter.Seq2[string, error] {
+	return b.FilesSeq("data", file)
+}
+
+func FilesSeq(category, file string) iter.Seq2[string, error] {
+	return defaults().FilesSeq(category, file)
+}
+func ConfigFilesSeq(file string) iter.Seq2[string, error] { return defaults().ConfigFilesSeq(file) }
+func DataFilesSeq(file string) iter.Seq2[string, error]   { return defaults().DataFilesSeq(file) }
+
+func filesSeq(file string, paths []string) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		for _, dir := range paths {
+			p := join(dir, file)
+			_, err := os.Stat(p)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if !yield(p, err) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"os"
+	"runtime"
+)
+
+// ServiceMode selects whether the base directories are resolved for a
+// system service instead of for a user.
+type ServiceMode int
+
+const (
+	// ServiceOff resolves the directories for the user, which is the default.
+	ServiceOff ServiceMode = iota
+
+	// ServiceAuto resolves the directories for a system service if the
+	// process runs as root, and for the user otherwise.
+	ServiceAuto
+
+	// ServiceOn always resolves the directories for a system service.
+	ServiceOn
+)
+
+// Service selects the mode in which the base directories are resolved.
+// Daemons usually want the directories of the Filesystem Hierarchy Standard
+// when they run as root, and the XDG directories otherwise. For a system
+// service, the defaults of the user base directories are:
+//
+//	ConfigHome      /etc
+//	DataHome        /var/lib
+//	CacheHome       /var/cache
+//	StateHome       /var/lib
+//	BinHome         /usr/local/bin
+//	RuntimeDir      /run
+//
+// so that App("dromi").ConfigDir() is /etc/dromi, StateDir is /var/lib/dromi,
+// and so on. XDG variables that are set are honored in every mode, and
+// ConfigDirs and DataDirs keep their defaults. BaseDirs.Service reports
+// whether the service directories are in use. The mode has no effect on
+// Windows.
+//
+// ServiceOn may also be used by a service that runs as its own user, but
+// such a user usually cannot create directories in /etc, /var/lib, or /run.
+// The owner and mode of /run are never changed, and the directories of the
+// service should be created beforehand, e.g. with the RuntimeDirectory=,
+// StateDirectory=, and ConfigurationDirectory= settings of systemd.
+//
+// If you change Service after the package has been initialized, you need
+// to call Init() again.
+var Service = ServiceOff
+
+// enabled returns true if the directories should be resolved for a system
+// service.
+func (m ServiceMode) enabled() bool {
+	if runtime.GOOS == "windows" {
+		return false
+	}
+	switch m {
+	case ServiceOn:
+		return true
+	case ServiceAuto:
+		return os.Geteuid() == 0
+	}
+	return false
+}
+
+// serviceDefaults returns the defaults of the base directories of a system
+// service.
+func serviceDefaults(getenv func(string) string) defaultDirs {
+	def := specDefaults(getenv)
+	def.configHome = "/etc"
+	def.dataHome = "/var/lib"
+	def.cacheHome = "/var/cache"
+	def.stateHome = "/var/lib"
+	def.binHome = "/usr/local/bin"
+	def.runtimeDir = "/run"
+	return def
+}
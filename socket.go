@@ -0,0 +1,88 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"errors"
+	"net"
+	"os"
+	"runtime"
+	"time"
+)
+
+// ErrSocketPath is returned by ListenRuntimeSocket and DialRuntimeSocket if
+// the path of the socket is too long for a Unix domain socket address.
+var ErrSocketPath = errors.New("socket path too long")
+
+// maxSocketPath returns the maximum length of the path of a Unix domain
+// socket, which is the size of sun_path minus the terminating NUL.
+func maxSocketPath() int {
+	if runtime.GOOS == "linux" || runtime.GOOS == "android" || runtime.GOOS == "windows" {
+		return 107
+	}
+	return 103
+}
+
+// ListenRuntimeSocket listens on the Unix domain socket file in RuntimeDir,
+// e.g. "dromi/control.sock", creating the directories leading to it with mode
+// 0700. If the socket file already exists, but no process accepts
+// connections on it, it is considered stale and replaced. If another process
+// is listening on it, the error of net.Listen is returned.
+//
+// The socket file is removed when the listener is closed, and is tracked for
+// CleanupRuntime.
+func (b *BaseDirs) ListenRuntimeSocket(file string) (net.Listener, error) {
+	p, err := b.runtimePath("listen", file)
+	if err != nil {
+		return nil, err
+	}
+	if len(p) > maxSocketPath() {
+		return nil, &os.PathError{Op: "listen", Path: p, Err: ErrSocketPath}
+	}
+
+	l, err := net.Listen("unix", p)
+	if err != nil {
+		if fi, serr := os.Lstat(p); serr != nil || fi.Mode()&os.ModeSocket == 0 {
+			return nil, err
+		}
+		if c, derr := net.DialTimeout("unix", p, time.Second); derr == nil {
+			c.Close()
+			return nil, err
+		}
+		if err := os.Remove(p); err != nil {
+			return nil, err
+		}
+		if l, err = net.Listen("unix", p); err != nil {
+			return nil, err
+		}
+	}
+	if err := b.chown(p); err != nil {
+		l.Close()
+		return nil, err
+	}
+	TrackRuntimeFile(p)
+	return l, nil
+}
+
+// DialRuntimeSocket connects to the Unix domain socket file in RuntimeDir,
+// on which another process listens with ListenRuntimeSocket.
+func (b *BaseDirs) DialRuntimeSocket(file string) (net.Conn, error) {
+	if b.RuntimeDir == "" {
+		return nil, errUnresolved("XDG_RUNTIME_DIR")
+	}
+	p := join(b.RuntimeDir, file)
+	if p == "" {
+		return nil, errInvalidFile("dial", file)
+	}
+	if len(p) > maxSocketPath() {
+		return nil, &os.PathError{Op: "dial", Path: p, Err: ErrSocketPath}
+	}
+	return net.Dial("unix", p)
+}
+
+func ListenRuntimeSocket(file string) (net.Listener, error) {
+	return defaults().ListenRuntimeSocket(file)
+}
+func DialRuntimeSocket(file string) (net.Conn, error) { return defaults().DialRuntimeSocket(file) }
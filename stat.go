@@ -0,0 +1,67 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"io/fs"
+	"os"
+)
+
+// Exists returns true if file of category exists in one of its base
+// directories, i.e., if Find would find it.
+func (b *BaseDirs) Exists(category, file string) bool { return b.Find(category, file) != "" }
+
+// Stat returns the FileInfo of the file of category that Find would find,
+// together with the base directory in which it was found. If the file is
+// not found, an error wrapping fs.ErrNotExist is returned.
+func (b *BaseDirs) Stat(category, file string) (fs.FileInfo, string, error) {
+	for _, dir := range b.Paths(category) {
+		fi, err := os.Stat(join(dir, file))
+		if err != nil {
+			continue
+		}
+		return fi, dir, nil
+	}
+	return nil, "", &fs.PathError{Op: "stat", Path: file, Err: fs.ErrNotExist}
+}
+
+func (b *BaseDirs) ConfigExists(file string) bool  { return b.Exists("config", file) }
+func (b *BaseDirs) DataExists(file string) bool    { return b.Exists("data", file) }
+func (b *BaseDirs) CacheExists(file string) bool   { return b.Exists("cache", file) }
+func (b *BaseDirs) StateExists(file string) bool   { return b.Exists("state", file) }
+func (b *BaseDirs) RuntimeExists(file string) bool { return b.Exists("runtime", file) }
+
+func (b *BaseDirs) ConfigStat(file string) (fs.FileInfo, string, error) {
+	return b.Stat("config", file)
+}
+func (b *BaseDirs) DataStat(file string) (fs.FileInfo, string, error) {
+	return b.Stat("data", file)
+}
+func (b *BaseDirs) CacheStat(file string) (fs.FileInfo, string, error) {
+	return b.Stat("cache", file)
+}
+func (b *BaseDirs) StateStat(file string) (fs.FileInfo, string, error) {
+	return b.Stat("state", file)
+}
+func (b *BaseDirs) RuntimeStat(file string) (fs.FileInfo, string, error) {
+	return b.Stat("runtime", file)
+}
+
+func Exists(category, file string) bool { return defaults().Exists(category, file) }
+func Stat(category, file string) (fs.FileInfo, string, error) {
+	return defaults().Stat(category, file)
+}
+
+func ConfigExists(file string) bool  { return defaults().ConfigExists(file) }
+func DataExists(file string) bool    { return defaults().DataExists(file) }
+func CacheExists(file string) bool   { return defaults().CacheExists(file) }
+func StateExists(file string) bool   { return defaults().StateExists(file) }
+func RuntimeExists(file string) bool { return defaults().RuntimeExists(file) }
+
+func ConfigStat(file string) (fs.FileInfo, string, error)  { return defaults().ConfigStat(file) }
+func DataStat(file string) (fs.FileInfo, string, error)    { return defaults().DataStat(file) }
+func CacheStat(file string) (fs.FileInfo, string, error)   { return defaults().CacheStat(file) }
+func StateStat(file string) (fs.FileInfo, string, error)   { return defaults().StateStat(file) }
+func RuntimeStat(file string) (fs.FileInfo, string, error) { return defaults().RuntimeStat(file) }
@@ -0,0 +1,63 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import "encoding/json"
+
+// State returns the resolved directories of b, the errors that occurred,
+// and the defaults and fallbacks that were used, as a map that can be
+// serialized for bug reports or telemetry. The keys are stable:
+//
+//	config_home, data_home, cache_home, state_home, bin_home, runtime_dir,
+//	config_dirs, data_dirs, home, home_source, home_fallback, temp_dir,
+//	runtime_source, sandbox, service, sudo_user, defaults, errors
+//
+// where defaults lists the XDG variables that were not set, so that their
+// defaults were used, and errors contains the messages of b.Errors.
+func (b *BaseDirs) State() map[string]any {
+	var defaults []string
+	for _, env := range []string{
+		"XDG_CONFIG_HOME", "XDG_DATA_HOME", "XDG_CACHE_HOME", "XDG_STATE_HOME",
+		"XDG_BIN_HOME", "XDG_RUNTIME_DIR", "XDG_CONFIG_DIRS", "XDG_DATA_DIRS",
+	} {
+		if b.getenv == nil || b.getenv(env) == "" {
+			defaults = append(defaults, env)
+		}
+	}
+	errs := make([]string, len(b.Errors))
+	for i, err := range b.Errors {
+		errs[i] = err.Error()
+	}
+	return map[string]any{
+		"config_home":    b.ConfigHome,
+		"data_home":      b.DataHome,
+		"cache_home":     b.CacheHome,
+		"state_home":     b.StateHome,
+		"bin_home":       b.BinHome,
+		"runtime_dir":    b.RuntimeDir,
+		"config_dirs":    b.ConfigDirs,
+		"data_dirs":      b.DataDirs,
+		"home":           b.Home,
+		"home_source":    b.HomeSource.String(),
+		"home_fallback":  b.HomeFallback,
+		"temp_dir":       b.TempDir,
+		"runtime_source": b.RuntimeSource.String(),
+		"sandbox": map[string]any{
+			"kind":   b.Sandbox.Kind.String(),
+			"app_id": b.Sandbox.AppID,
+			"home":   b.Sandbox.Home,
+		},
+		"service":   b.Service,
+		"sudo_user": b.SudoUser,
+		"defaults":  defaults,
+		"errors":    errs,
+	}
+}
+
+// MarshalJSON encodes the State of b, since the fields of b alone do not
+// serialize well: errors would be empty objects, and enumerations numbers.
+func (b *BaseDirs) MarshalJSON() ([]byte, error) { return json.Marshal(b.State()) }
+
+func State() map[string]any { return defaults().State() }
@@ -0,0 +1,37 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+//go:build darwin || freebsd
+
+package xdg
+
+import (
+	"strings"
+	"syscall"
+)
+
+// fsType returns the type of the file system that dir is on, if that can
+// be detected. FUSE file systems, such as macfuse or fusefs.sshfs, are
+// reported as "fuse".
+func fsType(dir string) (string, bool) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(dir, &st); err != nil {
+		return "", false
+	}
+	b := make([]byte, 0, len(st.Fstypename))
+	for _, c := range st.Fstypename {
+		if c == 0 {
+			break
+		}
+		b = append(b, byte(c))
+	}
+	name := string(b)
+	switch {
+	case strings.Contains(name, "fuse"):
+		return "fuse", true
+	case name == "smbfs":
+		return "smb", true
+	}
+	return name, true
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import "syscall"
+
+// fsNames maps the magic numbers of file systems, from statfs(2), to the
+// names that fsType returns.
+var fsNames = map[uint32]string{
+	0x6969:     "nfs",
+	0x517b:     "smb",
+	0xff534d42: "cifs",
+	0xfe534d42: "smb2",
+	0x5346414f: "afs",
+	0x73757245: "coda",
+	0x564c:     "ncp",
+	0x65735546: "fuse",
+	0x794c7630: "overlay",
+	0x01021994: "tmpfs",
+	0x858458f6: "ramfs",
+	0xef53:     "ext4",
+	0x9123683e: "btrfs",
+	0x58465342: "xfs",
+	0x2fc12fc1: "zfs",
+	0xf2f52010: "f2fs",
+}
+
+// fsType returns the type of the file system that dir is on, if that can
+// be detected.
+func fsType(dir string) (string, bool) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(dir, &st); err != nil {
+		return "", false
+	}
+	if name, ok := fsNames[uint32(st.Type)]; ok {
+		return name, true
+	}
+	return "other", true
+}
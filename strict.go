@@ -0,0 +1,66 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import "path/filepath"
+
+// Level is the level of compliance with the specification with which the
+// environment is resolved.
+type Level int
+
+const (
+	// Standard is the default level: relative paths are ignored, as the
+	// specification requires, empty elements of lists such as
+	// $XDG_DATA_DIRS are skipped, and if $XDG_RUNTIME_DIR is not set, a
+	// replacement directory is used, as the specification recommends.
+	Standard Level = iota
+
+	// Strict is like Standard, but treats an unset $XDG_RUNTIME_DIR as an
+	// error, which leaves RuntimeDir blank, for programs that must not run
+	// with a replacement that lacks the guarantees of the specification.
+	Strict
+
+	// Lenient is like Standard, but converts relative paths to absolute
+	// paths, relative to the working directory, instead of ignoring them.
+	Lenient
+
+	// Permissive is like Standard, but accepts relative paths as they are,
+	// and treats empty elements of lists as the working directory ".", as
+	// many programs that predate the specification did.
+	Permissive
+)
+
+func (l Level) String() string {
+	switch l {
+	case Standard:
+		return "standard"
+	case Strict:
+		return "strict"
+	case Lenient:
+		return "lenient"
+	case Permissive:
+		return "permissive"
+	}
+	return "unknown"
+}
+
+// Strictness is the level of compliance with the specification with which
+// the environment is resolved. It is independent of Expand, which is
+// applied to the values before they are checked. If you change Strictness
+// after the package has been initialized, you need to call Init() again.
+var Strictness = Standard
+
+// relative returns the path that is used for the relative path x at level
+// l, or false if x is to be ignored.
+func (l Level) relative(x string) (string, bool) {
+	switch l {
+	case Lenient:
+		p, err := filepath.Abs(x)
+		return p, err == nil
+	case Permissive:
+		return x, true
+	}
+	return "", false
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+)
+
+// Sudo enables an additional resolution step for programs that are run with
+// sudo, such as system tools that edit the configuration of a user: if the
+// process runs as root and $SUDO_USER is set, the base directories are
+// resolved against the home directory of the user who invoked sudo, rather
+// than that of root. Directories and files that the package creates then
+// belong to that user, so that they are not left owned by root in the home
+// directory of the user. BaseDirs.SudoUser reports whether this happened.
+//
+// If you change Sudo after the package has been initialized, you need to
+// call Init() again.
+var Sudo = false
+
+// sudoUser is the user who invoked sudo.
+type sudoUser struct {
+	name     string
+	home     string
+	uid, gid int
+}
+
+// lookupSudoUser returns the user who invoked sudo, as described by
+// $SUDO_USER, $SUDO_UID, and $SUDO_GID, if Sudo is set and the process
+// runs as root.
+func lookupSudoUser(getenv func(string) string) (sudoUser, bool) {
+	name := getenv("SUDO_USER")
+	if !Sudo || name == "" || name == "root" || os.Geteuid() != 0 {
+		return sudoUser{}, false
+	}
+	u, err := user.Lookup(name)
+	if err != nil {
+		return sudoUser{}, false
+	}
+	s := sudoUser{name: name, home: u.HomeDir}
+	if s.uid, err = strconv.Atoi(getenv("SUDO_UID")); err != nil {
+		if s.uid, err = strconv.Atoi(u.Uid); err != nil {
+			return sudoUser{}, false
+		}
+	}
+	if s.gid, err = strconv.Atoi(getenv("SUDO_GID")); err != nil {
+		if s.gid, err = strconv.Atoi(u.Gid); err != nil {
+			return sudoUser{}, false
+		}
+	}
+	return s, true
+}
+
+// owner returns the user and group that created files should belong to.
+func (b *BaseDirs) owner() (uid, gid int) {
+	if b.SudoUser != "" {
+		return b.uid, b.gid
+	}
+	return os.Getuid(), os.Getgid()
+}
+
+// chown changes the owner of p to the user who invoked sudo, if the
+// directories were resolved for that user.
+func (b *BaseDirs) chown(p string) error {
+	if b.SudoUser == "" {
+		return nil
+	}
+	return os.Lchown(p, b.uid, b.gid)
+}
+
+// openCreate opens the file p for reading and writing, creating it with mode
+// 0600 if necessary, and changes its owner as chown does.
+func (b *BaseDirs) openCreate(p string) (*os.File, error) {
+	f, err := os.OpenFile(p, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.chown(p); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// createTemp is like os.CreateTemp, but also changes the owner of the file,
+// as chown does.
+func (b *BaseDirs) createTemp(dir, pattern string) (*os.File, error) {
+	f, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.chown(f.Name()); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return f, nil
+}
+
+// mkdirTemp is like os.MkdirTemp, but also changes the owner of the
+// directory, as chown does.
+func (b *BaseDirs) mkdirTemp(dir, pattern string) (string, error) {
+	d, err := os.MkdirTemp(dir, pattern)
+	if err != nil {
+		return "", err
+	}
+	if err := b.chown(d); err != nil {
+		os.Remove(d)
+		return "", err
+	}
+	return d, nil
+}
+
+// mkdirAll is like os.MkdirAll, but also changes the owner of the
+// directories that it creates, as chown does.
+func (b *BaseDirs) mkdirAll(dir string, perm os.FileMode) error {
+	if b.SudoUser == "" {
+		return os.MkdirAll(dir, perm)
+	}
+	var created []string
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Lstat(d); err == nil {
+			break
+		}
+		created = append(created, d)
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	if err := os.MkdirAll(dir, perm); err != nil {
+		return err
+	}
+	for _, d := range created {
+		if err := b.chown(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
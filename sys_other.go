@@ -0,0 +1,32 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+//go:build !unix
+
+package xdg
+
+import (
+	"io/fs"
+	"os"
+)
+
+func fileUID(fi fs.FileInfo) (int, bool) { return 0, false }
+
+// processAlive returns true if the process pid exists.
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
+
+// canWrite returns true if the process may create files in the directory
+// dir, as far as its permissions tell.
+func canWrite(dir string, fi fs.FileInfo) bool { return fi.Mode().Perm()&0200 != 0 }
+
+// syncDir does nothing: directories cannot be synced on Windows, where
+// Sync fails with access denied, nor on js and wasip1.
+func syncDir(dir string) error { return nil }
@@ -0,0 +1,45 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+//go:build unix
+
+package xdg
+
+import (
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// fileUID returns the user ID of the owner of fi, if it is known.
+func fileUID(fi fs.FileInfo) (int, bool) {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return int(st.Uid), true
+}
+
+// processAlive returns true if the process pid exists.
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
+
+// canWrite returns true if the process may create files in the directory
+// dir, according to access(2).
+func canWrite(dir string, fi fs.FileInfo) bool { return syscall.Access(dir, 0x2|0x1) == nil }
+
+// syncDir syncs the directory dir, so that a rename in it is durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if cerr := d.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,18 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+//go:build !windows
+
+package xdg
+
+import "runtime"
+
+// systemTempDir returns the temporary directory of the system, as
+// os.TempDir does if $TMPDIR is not set.
+func systemTempDir(getenv func(string) string) string {
+	if runtime.GOOS == "android" {
+		return "/data/local/tmp"
+	}
+	return "/tmp"
+}
@@ -0,0 +1,346 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+// Package trash moves files to the trash and back, as defined by the
+// freedesktop.org Trash specification, so that file managers see them:
+//
+//	item, err := trash.Trash("notes.txt")
+//	err = trash.Restore(item)
+//
+// The trash of the user is the directory Trash in the XDG data directory,
+// e.g. ~/.local/share/Trash. A trashed file is moved to its subdirectory
+// files, and a .trashinfo file in its subdirectory info records where the
+// file came from and when it was trashed.
+//
+// Files on other file systems than the trash cannot be moved there; the
+// trash directories at the top of other file systems are not supported.
+package trash
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/goulash/xdg"
+	"github.com/goulash/xdg/keyfile"
+)
+
+// ErrCrossDevice is returned by Trash if the file is on another file system
+// than the trash.
+var ErrCrossDevice = errors.New("file is on another file system than the trash")
+
+// group is the group of a .trashinfo file.
+const group = "Trash Info"
+
+// dateFormat is the format of DeletionDate, in local time.
+const dateFormat = "2006-01-02T15:04:05"
+
+// Can is a trash directory.
+type Can struct {
+	b   *xdg.BaseDirs
+	dir string
+}
+
+// Item is a file in the trash.
+type Item struct {
+	// Name is the name of the file in the trash, which is unique within
+	// the trash, but not necessarily the name of the original file.
+	Name string
+
+	// Path is the absolute path that the file was trashed from.
+	Path string
+
+	// DeletionDate is the time at which the file was trashed.
+	DeletionDate time.Time
+}
+
+// Open opens the trash of the user in the XDG data directory, creating it
+// if necessary.
+func Open() (*Can, error) { return OpenDirs(nil) }
+
+// OpenDirs is like Open, but uses the data directory of b. If b is nil, the
+// default base directories of package xdg are used. The trash is created
+// in the same way as the other directories of b, so that it belongs to the
+// user who invoked sudo if b was resolved for that user; see xdg.Sudo.
+func OpenDirs(b *xdg.BaseDirs) (*Can, error) {
+	if b == nil {
+		b = xdg.Default()
+	}
+	if b.DataHome == "" {
+		return nil, fmt.Errorf("trash: %w", xdg.ErrInvalidPath)
+	}
+	c := &Can{b: b, dir: filepath.Join(b.DataHome, "Trash")}
+	_, err := os.Stat(c.dir)
+	created := errors.Is(err, fs.ErrNotExist)
+	for _, d := range []string{"Trash/files", "Trash/info"} {
+		if _, err := b.EnsureDataDir(d); err != nil {
+			return nil, err
+		}
+	}
+	if created {
+		// Only the user may see what is in the trash.
+		if err := os.Chmod(c.dir, 0700); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Dir returns the trash directory.
+func (c *Can) Dir() string { return c.dir }
+
+func (c *Can) files() string { return filepath.Join(c.dir, "files") }
+func (c *Can) info() string  { return filepath.Join(c.dir, "info") }
+
+func (c *Can) infoFile(name string) string {
+	return filepath.Join(c.info(), name+".trashinfo")
+}
+
+// Trash moves the file or directory path to the trash. If it is on another
+// file system than the trash, the error wraps ErrCrossDevice.
+func (c *Can) Trash(path string) (Item, error) {
+	p, err := filepath.Abs(path)
+	if err != nil {
+		return Item{}, err
+	}
+	if _, err := os.Lstat(p); err != nil {
+		return Item{}, err
+	}
+	if within(p, c.dir) || within(c.dir, p) {
+		return Item{}, &fs.PathError{Op: "trash", Path: path, Err: fs.ErrInvalid}
+	}
+
+	it := Item{Path: p, DeletionDate: time.Now().Truncate(time.Second)}
+	f, name, err := c.create(filepath.Base(p))
+	if err != nil {
+		return Item{}, err
+	}
+	it.Name = name
+	_, err = f.WriteString(it.info())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(p, filepath.Join(c.files(), name))
+		if errors.Is(err, syscall.EXDEV) {
+			err = &fs.PathError{Op: "trash", Path: path, Err: ErrCrossDevice}
+		}
+	}
+	if err != nil {
+		os.Remove(c.infoFile(name))
+		return Item{}, err
+	}
+	return it, nil
+}
+
+// within returns true if p is dir or in dir.
+func within(p, dir string) bool {
+	rel, err := filepath.Rel(dir, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// create creates the .trashinfo file of a new item named after base, which
+// reserves the name. If the name is taken, a number is appended to it.
+func (c *Can) create(base string) (*os.File, string, error) {
+	for i := 1; ; i++ {
+		name := base
+		if i > 1 {
+			name = base + "." + strconv.Itoa(i)
+		}
+		if _, err := os.Lstat(filepath.Join(c.files(), name)); err == nil {
+			continue
+		}
+		flag := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+		f, err := c.b.OpenDataFile("Trash/info/"+name+".trashinfo", flag, 0600)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		return f, name, err
+	}
+}
+
+// info returns the content of the .trashinfo file of it.
+func (it Item) info() string {
+	u := url.URL{Path: filepath.ToSlash(it.Path)}
+	return "[" + group + "]\n" +
+		"Path=" + u.EscapedPath() + "\n" +
+		"DeletionDate=" + it.DeletionDate.Format(dateFormat) + "\n"
+}
+
+// List returns the items in the trash. Items whose .trashinfo file cannot
+// be read are skipped, and their errors are joined into the returned error.
+func (c *Can) List() ([]Item, error) {
+	ms, err := filepath.Glob(filepath.Join(c.info(), "*.trashinfo"))
+	if err != nil {
+		return nil, err
+	}
+	var (
+		its  []Item
+		errs []error
+	)
+	for _, m := range ms {
+		name := strings.TrimSuffix(filepath.Base(m), ".trashinfo")
+		it, err := c.read(name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		its = append(its, it)
+	}
+	return its, errors.Join(errs...)
+}
+
+// read reads the .trashinfo file of the item name.
+func (c *Can) read(name string) (Item, error) {
+	p := c.infoFile(name)
+	f, err := keyfile.ParseFile(p)
+	if err != nil {
+		return Item{}, err
+	}
+	it := Item{Name: name}
+	v, ok := f.Value(group, "Path")
+	if !ok {
+		return Item{}, fmt.Errorf("%s: missing Path", p)
+	}
+	if it.Path, err = url.PathUnescape(v); err != nil {
+		return Item{}, fmt.Errorf("%s: %w", p, err)
+	}
+	it.Path = filepath.FromSlash(it.Path)
+	if !filepath.IsAbs(it.Path) {
+		// Relative paths are only used in the trash directories at the top
+		// of other file systems.
+		return Item{}, fmt.Errorf("%s: path %q is not absolute", p, it.Path)
+	}
+	if v, ok := f.Value(group, "DeletionDate"); ok {
+		if it.DeletionDate, err = time.ParseInLocation(dateFormat, v, time.Local); err != nil {
+			return Item{}, fmt.Errorf("%s: %w", p, err)
+		}
+	}
+	return it, nil
+}
+
+// Restore moves it back to its original path, creating the directories
+// leading to it if necessary. If a file already exists at that path, the
+// error wraps fs.ErrExist and the item stays in the trash; an existing file
+// is never replaced, even if it is created while Restore runs.
+//
+// The original path is read from the .trashinfo file of the item, not
+// taken from it. If the Name of it is not a file name in the trash, or its
+// Path is set but differs from the recorded one, the error wraps
+// fs.ErrInvalid.
+func (c *Can) Restore(it Item) error {
+	if it.Name == "" || it.Name == "." || it.Name == ".." || filepath.Base(it.Name) != it.Name {
+		return &fs.PathError{Op: "restore", Path: it.Name, Err: fs.ErrInvalid}
+	}
+	rec, err := c.read(it.Name)
+	if err != nil {
+		return err
+	}
+	if it.Path != "" && it.Path != rec.Path {
+		return &fs.PathError{Op: "restore", Path: it.Path, Err: fs.ErrInvalid}
+	}
+	if err := os.MkdirAll(filepath.Dir(rec.Path), 0755); err != nil {
+		return err
+	}
+	if err := move(filepath.Join(c.files(), rec.Name), rec.Path); err != nil {
+		return err
+	}
+	return os.Remove(c.infoFile(rec.Name))
+}
+
+// move moves src to dst, but unlike os.Rename never replaces dst. A file is
+// linked to dst, which fails if dst exists, and then removed. A directory
+// cannot be linked, so it is renamed onto an empty directory created at
+// dst, which rename(2) only replaces while it is still empty; os.Rename
+// cannot be used for that, since it refuses to replace a directory.
+func move(src, dst string) error {
+	fi, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	if !fi.IsDir() {
+		if err := os.Link(src, dst); err != nil {
+			return err
+		}
+		return os.Remove(src)
+	}
+	if err := os.Mkdir(dst, 0700); err != nil {
+		return err
+	}
+	if err := syscall.Rename(src, dst); err != nil {
+		os.Remove(dst)
+		if errors.Is(err, syscall.ENOTEMPTY) {
+			err = fs.ErrExist
+		}
+		return &os.LinkError{Op: "rename", Old: src, New: dst, Err: err}
+	}
+	return nil
+}
+
+// Empty permanently deletes the items that were trashed more than olderThan
+// ago; if olderThan is 0, all items are deleted. Items are deleted as far
+// as possible; the errors are joined into the returned error.
+func (c *Can) Empty(olderThan time.Duration) error {
+	its, err := c.List()
+	errs := []error{err}
+	cutoff := time.Now().Add(-olderThan)
+	for _, it := range its {
+		if olderThan > 0 && it.DeletionDate.After(cutoff) {
+			continue
+		}
+		// The file is removed first, so that an item that cannot be
+		// removed completely is still listed.
+		if err := os.RemoveAll(filepath.Join(c.files(), it.Name)); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := os.Remove(c.infoFile(it.Name)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Trash moves path to the trash of the user; see Can.Trash.
+func Trash(path string) (Item, error) {
+	c, err := Open()
+	if err != nil {
+		return Item{}, err
+	}
+	return c.Trash(path)
+}
+
+// List returns the items in the trash of the user; see Can.List.
+func List() ([]Item, error) {
+	c, err := Open()
+	if err != nil {
+		return nil, err
+	}
+	return c.List()
+}
+
+// Restore moves it back from the trash of the user; see Can.Restore.
+func Restore(it Item) error {
+	c, err := Open()
+	if err != nil {
+		return err
+	}
+	return c.Restore(it)
+}
+
+// Empty empties the trash of the user; see Can.Empty.
+func Empty(olderThan time.Duration) error {
+	c, err := Open()
+	if err != nil {
+		return err
+	}
+	return c.Empty(olderThan)
+}
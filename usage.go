@@ -0,0 +1,101 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"sort"
+)
+
+// Usage describes the disk usage of a directory, as reported by DiskUsage.
+type Usage struct {
+	Bytes   int64        // total size of the regular files
+	Files   int          // number of regular files
+	Largest []UsageEntry // largest files, at most UsageLargest, largest first
+}
+
+// UsageEntry is a file counted by DiskUsage.
+type UsageEntry struct {
+	Path string
+	Size int64
+}
+
+// UsageLargest is the number of largest files that DiskUsage reports.
+var UsageLargest = 10
+
+// DiskUsage returns the disk usage of the directory prefix in the user
+// base directory of category, e.g. DiskUsage("cache", "dromi") for
+// ~/.cache/dromi, so that an application can show how much space its cache
+// takes. If prefix is "", the whole base directory is counted. Only regular
+// files are counted, and symbolic links are not followed.
+//
+// If prefix does not exist, the Usage is empty. Files that cannot be read
+// do not stop the walk; the errors are joined and returned together with
+// the usage of the other files.
+func (b *BaseDirs) DiskUsage(category, prefix string) (Usage, error) {
+	d, ok := b.category(category)
+	if !ok {
+		return Usage{}, ErrUnknownCategory
+	}
+	if d.home == "" {
+		return Usage{}, errUnresolved(d.env)
+	}
+	root := join(d.home, prefix)
+	if root == "" {
+		return Usage{}, errInvalidFile("usage", prefix)
+	}
+
+	var (
+		u    Usage
+		errs []error
+	)
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == root && errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipAll
+			}
+			errs = append(errs, err)
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+		u.Bytes += fi.Size()
+		u.Files++
+		u.addLargest(UsageEntry{Path: p, Size: fi.Size()})
+		return nil
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return u, errors.Join(errs...)
+}
+
+// addLargest adds e to u.Largest if it is one of the largest files.
+func (u *Usage) addLargest(e UsageEntry) {
+	n := UsageLargest
+	if n <= 0 {
+		return
+	}
+	if len(u.Largest) == n && e.Size <= u.Largest[n-1].Size {
+		return
+	}
+	i := sort.Search(len(u.Largest), func(i int) bool { return u.Largest[i].Size < e.Size })
+	u.Largest = append(u.Largest, UsageEntry{})
+	copy(u.Largest[i+1:], u.Largest[i:])
+	u.Largest[i] = e
+	if len(u.Largest) > n {
+		u.Largest = u.Largest[:n]
+	}
+}
+
+func DiskUsage(category, prefix string) (Usage, error) { return defaults().DiskUsage(category, prefix) }
@@ -0,0 +1,347 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// UserDirectories contains the well-known user directories of the
+// xdg-user-dirs tool, such as the directory for downloads. Unlike the base
+// directories, they are not defined by environment variables, but in the
+// file user-dirs.dirs in ConfigHome, which xdg-user-dirs-update writes at
+// login. The names of the directories are usually localized.
+//
+// A directory is "" if it is not configured or it is disabled, which is
+// done by setting it to the home directory itself.
+type UserDirectories struct {
+	Desktop     string // XDG_DESKTOP_DIR, e.g. ~/Desktop
+	Download    string // XDG_DOWNLOAD_DIR, e.g. ~/Downloads
+	Templates   string // XDG_TEMPLATES_DIR, e.g. ~/Templates
+	PublicShare string // XDG_PUBLICSHARE_DIR, e.g. ~/Public
+	Documents   string // XDG_DOCUMENTS_DIR, e.g. ~/Documents
+	Music       string // XDG_MUSIC_DIR, e.g. ~/Music
+	Pictures    string // XDG_PICTURES_DIR, e.g. ~/Pictures
+	Videos      string // XDG_VIDEOS_DIR, e.g. ~/Videos
+}
+
+// ErrUnknownUserDir is returned by SetUserDir if the kind of user directory
+// is unknown.
+var ErrUnknownUserDir = errors.New("unknown XDG user directory")
+
+// UserDirs contains the user directories of the default BaseDirs. It is set
+// by Init together with the other package variables, and should also be
+// treated as read-only. Errors reading the files are ignored here; use
+// BaseDirs.UserDirs to get them.
+var UserDirs UserDirectories
+
+// setUserDirs reads the user directories of b, without holding mu, and sets
+// UserDirs to them if b is still the default BaseDirs.
+func setUserDirs(b *BaseDirs) {
+	u, _ := b.UserDirs()
+	mu.Lock()
+	defer mu.Unlock()
+	if std == b {
+		UserDirs = u
+	}
+}
+
+// userDirKinds are the kinds of user directories, as they are named in
+// user-dirs.defaults and, with XDG_ and _DIR around them, in user-dirs.dirs.
+var userDirKinds = []string{
+	"DESKTOP", "DOWNLOAD", "TEMPLATES", "PUBLICSHARE",
+	"DOCUMENTS", "MUSIC", "PICTURES", "VIDEOS",
+}
+
+// field returns a pointer to the field of u for kind, or nil if kind is
+// unknown.
+func (u *UserDirectories) field(kind string) *string {
+	switch kind {
+	case "DESKTOP":
+		return &u.Desktop
+	case "DOWNLOAD":
+		return &u.Download
+	case "TEMPLATES":
+		return &u.Templates
+	case "PUBLICSHARE":
+		return &u.PublicShare
+	case "DOCUMENTS":
+		return &u.Documents
+	case "MUSIC":
+		return &u.Music
+	case "PICTURES":
+		return &u.Pictures
+	case "VIDEOS":
+		return &u.Videos
+	}
+	return nil
+}
+
+// Get returns the user directory of kind, which is one of DESKTOP,
+// DOWNLOAD, TEMPLATES, PUBLICSHARE, DOCUMENTS, MUSIC, PICTURES, and VIDEOS
+// (in any case), or "" if kind is unknown.
+func (u UserDirectories) Get(kind string) string {
+	if p := u.field(strings.ToUpper(kind)); p != nil {
+		return *p
+	}
+	return ""
+}
+
+// UserDirs reads the user directories from user-dirs.dirs in ConfigHome.
+// Directories that the file does not mention are taken from the system
+// defaults in user-dirs.defaults in the first of ConfigDirs that has one,
+// e.g. /etc/xdg/user-dirs.defaults, in which they are relative to Home.
+// It is not an error if neither file exists.
+//
+// In user-dirs.dirs, a directory is either an absolute path or a path
+// relative to $HOME, such as "$HOME/Downloads"; other values are ignored.
+func (b *BaseDirs) UserDirs() (UserDirectories, error) {
+	var u UserDirectories
+	seen := make(map[string]bool)
+	if b.ConfigHome != "" {
+		data, err := os.ReadFile(filepath.Join(b.ConfigHome, "user-dirs.dirs"))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return u, err
+		}
+		for kind, v := range parseUserDirs(data, b.Home) {
+			*u.field(kind) = v
+			seen[kind] = true
+		}
+	}
+	if len(seen) == len(userDirKinds) {
+		return u, nil
+	}
+	p := find("user-dirs.defaults", b.ConfigDirs)
+	if p == "" {
+		return u, nil
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return u, err
+	}
+	if b.Home == "" {
+		return u, nil
+	}
+	for kind, v := range parseUserDirsDefaults(data) {
+		if !seen[kind] {
+			*u.field(kind) = userDir(b.Home, "/"+v)
+		}
+	}
+	return u, nil
+}
+
+// userDirsHeader is the comment that xdg-user-dirs-update writes at the top
+// of a new user-dirs.dirs.
+const userDirsHeader = `# This file is written by xdg-user-dirs-update
+# If you want to change or add directories, just edit the line you're
+# interested in. All local changes will be retained on the next run.
+# Format is XDG_xxx_DIR="$HOME/yyy", where yyy is a shell-escaped
+# homedir-relative path, or XDG_xxx_DIR="/yyy", where /yyy is an
+# absolute path. No other format is supported.
+#
+`
+
+// SetUserDir sets the user directory of kind, which is one of the kinds
+// that UserDirectories.Get accepts, to dir in user-dirs.dirs in ConfigHome.
+// If dir is inside Home, it is written relative to $HOME, as
+// xdg-user-dirs-update does. If dir is "", the directory is disabled.
+// The directory itself is not created.
+//
+// The file is replaced atomically; other lines, including comments and
+// unknown assignments, are preserved. If kind is unknown, the error is
+// ErrUnknownUserDir; if dir is not absolute, the error wraps ErrInvalidPath.
+func (b *BaseDirs) SetUserDir(kind, dir string) error {
+	kind = strings.ToUpper(kind)
+	if (&UserDirectories{}).field(kind) == nil {
+		return ErrUnknownUserDir
+	}
+	value, err := b.userDirValue(dir)
+	if err != nil {
+		return err
+	}
+	if b.ConfigHome == "" {
+		return errUnresolved("XDG_CONFIG_HOME")
+	}
+	data, err := os.ReadFile(filepath.Join(b.ConfigHome, "user-dirs.dirs"))
+	if errors.Is(err, fs.ErrNotExist) {
+		data = []byte(userDirsHeader)
+	} else if err != nil {
+		return err
+	}
+	return b.WriteConfigFile("user-dirs.dirs", setUserDirsLine(data, kind, value), 0644)
+}
+
+// userDirValue returns dir as it is written in user-dirs.dirs, quoted.
+func (b *BaseDirs) userDirValue(dir string) (string, error) {
+	if dir == "" {
+		if b.Home == "" {
+			return "", ErrInvalidHome
+		}
+		dir = b.Home
+	}
+	if err := checkAbs(dir); err != nil {
+		return "", err
+	}
+	dir = filepath.Clean(dir)
+	if b.Home != "" {
+		rel, err := filepath.Rel(b.Home, dir)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			if rel == "." {
+				rel = ""
+			}
+			return `"$HOME/` + shellEscape(filepath.ToSlash(rel)) + `"`, nil
+		}
+	}
+	return `"` + shellEscape(filepath.ToSlash(dir)) + `"`, nil
+}
+
+// shellEscape escapes the characters that are special inside double quotes
+// in a shell, in the same way as xdg-user-dirs-update.
+func shellEscape(s string) string {
+	var sb strings.Builder
+	for _, c := range s {
+		if strings.ContainsRune("\\\"$`", c) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(c)
+	}
+	return sb.String()
+}
+
+// setUserDirsLine returns data with the assignment of kind replaced by
+// value, or with the assignment appended if there is none.
+func setUserDirsLine(data []byte, kind, value string) []byte {
+	line := "XDG_" + kind + "_DIR=" + value
+	lines := strings.SplitAfter(string(data), "\n")
+	found := false
+	for i, l := range lines {
+		if k, _, ok := parseUserDirsLine(l); ok && k == kind {
+			if found {
+				// Drop repeated assignments, which would override ours.
+				lines[i] = ""
+				continue
+			}
+			lines[i] = line + "\n"
+			found = true
+		}
+	}
+	s := strings.Join(lines, "")
+	if !found {
+		if s != "" && !strings.HasSuffix(s, "\n") {
+			s += "\n"
+		}
+		s += line + "\n"
+	}
+	return []byte(s)
+}
+
+// SetUserDir sets a user directory in the same way as BaseDirs.SetUserDir,
+// and updates UserDirs.
+func SetUserDir(kind, dir string) error {
+	b := defaults()
+	if err := b.SetUserDir(kind, dir); err != nil {
+		return err
+	}
+	setUserDirs(b)
+	return nil
+}
+
+// parseUserDirs parses the content of user-dirs.dirs, which consists of
+// shell assignments such as XDG_DOWNLOAD_DIR="$HOME/Downloads", and returns
+// the directories by kind. Disabled directories are "".
+func parseUserDirs(data []byte, home string) map[string]string {
+	dirs := make(map[string]string)
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		kind, value, ok := parseUserDirsLine(s.Text())
+		if !ok {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(value, "$HOME"); ok {
+			if home == "" || (rest != "" && rest[0] != '/') {
+				continue
+			}
+			dirs[kind] = userDir(home, rest)
+		} else if filepath.IsAbs(value) {
+			if value = filepath.Clean(value); value == filepath.Clean(home) {
+				value = ""
+			}
+			dirs[kind] = value
+		}
+	}
+	return dirs
+}
+
+// parseUserDirsLine returns the kind and the unquoted value of a line of
+// user-dirs.dirs, or false if it is not an assignment of a known kind.
+func parseUserDirsLine(line string) (kind, value string, ok bool) {
+	line = strings.TrimSpace(line)
+	key, value, ok := strings.Cut(line, "=")
+	if !ok || strings.HasPrefix(key, "#") {
+		return "", "", false
+	}
+	kind, ok = strings.CutPrefix(key, "XDG_")
+	if ok {
+		kind, ok = strings.CutSuffix(kind, "_DIR")
+	}
+	if !ok || (&UserDirectories{}).field(kind) == nil {
+		return "", "", false
+	}
+	if len(value) < 2 || value[0] != '"' {
+		return "", "", false
+	}
+	var sb strings.Builder
+	for i := 1; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '\\':
+			if i++; i < len(value) {
+				sb.WriteByte(value[i])
+			}
+		case '"':
+			return kind, sb.String(), true
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	// The closing quote is missing.
+	return "", "", false
+}
+
+// parseUserDirsDefaults parses the content of user-dirs.defaults, which
+// consists of lines such as DOWNLOAD=Downloads, and returns the directories
+// by kind, relative to the home directory.
+func parseUserDirsDefaults(data []byte) map[string]string {
+	dirs := make(map[string]string)
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		kind, value, ok := strings.Cut(line, "=")
+		kind = strings.ToUpper(strings.TrimSpace(kind))
+		if !ok || (&UserDirectories{}).field(kind) == nil {
+			continue
+		}
+		dirs[kind] = strings.TrimSpace(value)
+	}
+	return dirs
+}
+
+// userDir returns the directory rel relative to home, or "" if it is home
+// itself, which disables the directory.
+func userDir(home, rel string) string {
+	p := filepath.Join(home, filepath.FromSlash(rel))
+	if p == filepath.Clean(home) {
+		return ""
+	}
+	return p
+}
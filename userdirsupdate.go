@@ -0,0 +1,235 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// builtinUserDirs are the defaults of xdg-user-dirs-update, which are used
+// if there is no user-dirs.defaults.
+var builtinUserDirs = map[string]string{
+	"DESKTOP":     "Desktop",
+	"DOWNLOAD":    "Downloads",
+	"TEMPLATES":   "Templates",
+	"PUBLICSHARE": "Public",
+	"DOCUMENTS":   "Documents",
+	"MUSIC":       "Music",
+	"PICTURES":    "Pictures",
+	"VIDEOS":      "Videos",
+}
+
+// UpdateUserDirs does what xdg-user-dirs-update does at login: each user
+// directory that user-dirs.dirs does not mention yet is set to its default
+// from user-dirs.defaults in ConfigDirs, created, and recorded in
+// user-dirs.dirs. Directories that are already configured, even if they
+// do not exist, are left alone, so that changes of the user are retained.
+//
+// The defaults are translated element by element into the language of the
+// user, as given by $LANGUAGE, $LC_ALL, $LC_MESSAGES, or $LANG, if the
+// xdg-user-dirs translations are installed in the locale directory of one
+// of DataDirs, e.g. /usr/share/locale/de/LC_MESSAGES/xdg-user-dirs.mo. The
+// language that was used is written to user-dirs.locale.
+//
+// Nothing is done if user-dirs.conf, in ConfigHome or ConfigDirs, contains
+// enabled=False.
+func (b *BaseDirs) UpdateUserDirs() error {
+	if !b.userDirsEnabled() {
+		return nil
+	}
+	if b.Home == "" {
+		return ErrInvalidHome
+	}
+	if b.ConfigHome == "" {
+		return errUnresolved("XDG_CONFIG_HOME")
+	}
+	data, err := os.ReadFile(filepath.Join(b.ConfigHome, "user-dirs.dirs"))
+	if errors.Is(err, fs.ErrNotExist) {
+		data = []byte(userDirsHeader)
+	} else if err != nil {
+		return err
+	}
+	configured := parseUserDirs(data, b.Home)
+
+	defaults := builtinUserDirs
+	if p := find("user-dirs.defaults", b.ConfigDirs); p != "" {
+		d, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		defaults = parseUserDirsDefaults(d)
+	}
+
+	lang, catalog := b.userDirsCatalog()
+	changed := false
+	for _, kind := range userDirKinds {
+		rel, ok := defaults[kind]
+		if _, done := configured[kind]; done || !ok {
+			continue
+		}
+		elems := strings.Split(rel, "/")
+		for i, e := range elems {
+			if t, ok := catalog[e]; ok && t != "" {
+				elems[i] = t
+			}
+		}
+		dir := userDir(b.Home, "/"+strings.Join(elems, "/"))
+		if dir != "" {
+			if err := b.mkdirAll(dir, 0755); err != nil {
+				return err
+			}
+		} else {
+			dir = b.Home
+		}
+		value, err := b.userDirValue(dir)
+		if err != nil {
+			return err
+		}
+		data = setUserDirsLine(data, kind, value)
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	if err := b.WriteConfigFile("user-dirs.dirs", data, 0644); err != nil {
+		return err
+	}
+	if lang == "" {
+		return nil
+	}
+	return b.WriteConfigFile("user-dirs.locale", []byte(lang+"\n"), 0644)
+}
+
+// UpdateUserDirs updates the user directories in the same way as
+// BaseDirs.UpdateUserDirs, and updates UserDirs.
+func UpdateUserDirs() error {
+	b := defaults()
+	if err := b.UpdateUserDirs(); err != nil {
+		return err
+	}
+	setUserDirs(b)
+	return nil
+}
+
+// userDirsEnabled returns false if the first user-dirs.conf that is found
+// disables xdg-user-dirs-update.
+func (b *BaseDirs) userDirsEnabled() bool {
+	data, err := os.ReadFile(b.FindConfig("user-dirs.conf"))
+	if err != nil {
+		return true
+	}
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		k, v, ok := strings.Cut(strings.TrimSpace(s.Text()), "=")
+		if ok && strings.TrimSpace(k) == "enabled" {
+			return !strings.EqualFold(strings.TrimSpace(v), "false")
+		}
+	}
+	return true
+}
+
+// userDirsCatalog returns the language of the user and the translations of
+// xdg-user-dirs into it, or "" and nil if there are none.
+func (b *BaseDirs) userDirsCatalog() (string, map[string]string) {
+	for _, lang := range b.languages() {
+		for _, dir := range b.DataPaths() {
+			p := filepath.Join(dir, "locale", lang, "LC_MESSAGES", "xdg-user-dirs.mo")
+			data, err := os.ReadFile(p)
+			if err != nil {
+				continue
+			}
+			if catalog := parseMO(data); catalog != nil {
+				return lang, catalog
+			}
+		}
+	}
+	return "", nil
+}
+
+// languages returns the languages in which gettext would look for
+// translations, in order of preference, e.g. de_DE and de for de_DE.UTF-8.
+func (b *BaseDirs) languages() []string {
+	var locale string
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if locale = b.Getenv(env); locale != "" {
+			break
+		}
+	}
+	if locale == "" || locale == "C" || locale == "POSIX" {
+		return nil
+	}
+	// $LANGUAGE is only honored if a locale is set.
+	list := strings.Split(b.Getenv("LANGUAGE"), ":")
+	list = append(list, locale)
+
+	var langs []string
+	for _, l := range list {
+		if i := strings.IndexAny(l, ".@"); i >= 0 {
+			l = l[:i]
+		}
+		if l == "" {
+			continue
+		}
+		langs = append(langs, l)
+		if lang, _, ok := strings.Cut(l, "_"); ok {
+			langs = append(langs, lang)
+		}
+	}
+	return langs
+}
+
+// parseMO parses a compiled gettext catalog and returns its translations,
+// or nil if data is not a valid catalog. Of plural forms, only the singular
+// is used.
+func parseMO(data []byte) map[string]string {
+	if len(data) < 20 {
+		return nil
+	}
+	var order binary.ByteOrder
+	switch binary.LittleEndian.Uint32(data) {
+	case 0x950412de:
+		order = binary.LittleEndian
+	case 0xde120495:
+		order = binary.BigEndian
+	default:
+		return nil
+	}
+	n := order.Uint32(data[8:])
+	orig, trans := order.Uint32(data[12:]), order.Uint32(data[16:])
+	str := func(table, i uint32) (string, bool) {
+		off := uint64(table) + 8*uint64(i)
+		if off+8 > uint64(len(data)) {
+			return "", false
+		}
+		l, o := uint64(order.Uint32(data[off:])), uint64(order.Uint32(data[off+4:]))
+		if o+l > uint64(len(data)) {
+			return "", false
+		}
+		s, _, _ := strings.Cut(string(data[o:o+l]), "\x00")
+		return s, true
+	}
+	catalog := make(map[string]string)
+	for i := uint32(0); i < n; i++ {
+		id, ok := str(orig, i)
+		if !ok {
+			return nil
+		}
+		t, ok := str(trans, i)
+		if !ok {
+			return nil
+		}
+		if id != "" {
+			catalog[id] = t
+		}
+	}
+	return catalog
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// Report describes how each XDG variable was resolved, as returned by
+// Validate. Its String method formats it for humans, e.g. for a --debug
+// flag.
+type Report struct {
+	Vars []VarReport
+}
+
+// VarReport describes how one XDG variable was resolved.
+type VarReport struct {
+	Var     string      // name of the variable, e.g. XDG_CONFIG_HOME
+	Raw     string      // value in the environment, or "" if it is not set
+	Default bool        // whether the default was used, as Raw was not set
+	Dirs    []DirReport // resolved directories, in order of preference
+	Errs    []error     // violations of the specification
+}
+
+// DirReport describes a resolved base directory.
+type DirReport struct {
+	Path     string
+	Exists   bool
+	Writable bool        // whether the process may create files in Path
+	Mode     fs.FileMode // mode of Path, if it exists
+}
+
+// OK returns true if no violations of the specification were found.
+func (r Report) OK() bool {
+	for _, v := range r.Vars {
+		if len(v.Errs) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Err returns all violations in r joined into one error, or nil.
+func (r Report) Err() error {
+	var errs []error
+	for _, v := range r.Vars {
+		errs = append(errs, v.Errs...)
+	}
+	return errors.Join(errs...)
+}
+
+func (r Report) String() string {
+	var sb strings.Builder
+	for _, v := range r.Vars {
+		if v.Default {
+			fmt.Fprintf(&sb, "%s (not set, default)\n", v.Var)
+		} else {
+			fmt.Fprintf(&sb, "%s=%s\n", v.Var, v.Raw)
+		}
+		for _, d := range v.Dirs {
+			switch {
+			case !d.Exists:
+				fmt.Fprintf(&sb, "\t%s: does not exist\n", d.Path)
+			case d.Writable:
+				fmt.Fprintf(&sb, "\t%s: %v, writable\n", d.Path, d.Mode)
+			default:
+				fmt.Fprintf(&sb, "\t%s: %v, read-only\n", d.Path, d.Mode)
+			}
+		}
+		for _, err := range v.Errs {
+			fmt.Fprintf(&sb, "\terror: %v\n", err)
+		}
+	}
+	return sb.String()
+}
+
+// Validate returns a report of how $HOME and each XDG variable were
+// resolved: the raw value, whether the default was used, the resulting
+// directories, whether they exist and can be written to, and any violations
+// of the specification, including those that ValidateRuntimeDir checks. It
+// does not modify the file system.
+func (b *BaseDirs) Validate() Report {
+	getenv := b.getenv
+	if getenv == nil {
+		getenv = func(string) string { return "" }
+	}
+	vars := []struct {
+		env  string
+		dirs []string
+	}{
+		{"HOME", []string{b.Home}},
+		{"XDG_CONFIG_HOME", []string{b.ConfigHome}},
+		{"XDG_DATA_HOME", []string{b.DataHome}},
+		{"XDG_CACHE_HOME", []string{b.CacheHome}},
+		{"XDG_STATE_HOME", []string{b.StateHome}},
+		{"XDG_BIN_HOME", []string{b.BinHome}},
+		{"XDG_RUNTIME_DIR", []string{b.RuntimeDir}},
+		{"XDG_CONFIG_DIRS", b.ConfigDirs},
+		{"XDG_DATA_DIRS", b.DataDirs},
+	}
+
+	var r Report
+	for _, x := range vars {
+		v := VarReport{Var: x.env, Raw: getenv(x.env)}
+		v.Default = v.Raw == ""
+		for _, dir := range x.dirs {
+			if dir == "" {
+				continue
+			}
+			d := DirReport{Path: dir}
+			if fi, err := os.Stat(dir); err == nil {
+				d.Exists, d.Mode = true, fi.Mode()
+				d.Writable = fi.IsDir() && canWrite(dir, fi)
+			}
+			v.Dirs = append(v.Dirs, d)
+		}
+		if err := b.ErrFor(x.env); err != nil {
+			v.Errs = append(v.Errs, err)
+		}
+		if x.env == "XDG_RUNTIME_DIR" && b.RuntimeDir != "" {
+			var re *RuntimeDirError
+			if err := b.ValidateRuntimeDir(); errors.As(err, &re) {
+				v.Errs = append(v.Errs, re.Errs...)
+			}
+		}
+		r.Vars = append(r.Vars, v)
+	}
+	return r
+}
+
+func Validate() Report { return defaults().Validate() }
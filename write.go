@@ -0,0 +1,89 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// ResolveWrite returns the path to which file of category should be written,
+// and creates the directories leading to it. Normally, this is the path in
+// the user base directory, but if that cannot be written to, for example
+// because the home directory is read-only, the first writable global base
+// directory is used instead.
+//
+// If file cannot be written in any of the base directories, the error of the
+// user base directory is returned.
+func (b *BaseDirs) ResolveWrite(category, file string) (string, error) {
+	d, ok := b.category(category)
+	if !ok {
+		return "", ErrUnknownCategory
+	}
+
+	dirs := b.Paths(category)
+	if len(dirs) == 0 {
+		return "", errUnresolved(d.env)
+	}
+	var first error
+	for _, dir := range dirs {
+		p := join(dir, file)
+		if p == "" {
+			return "", errInvalidFile("resolve", file)
+		}
+		err := b.mkdirAll(filepath.Dir(p), dirPerm(category))
+		if err == nil {
+			err = probeWrite(filepath.Dir(p))
+		}
+		if err == nil {
+			return p, nil
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	return "", first
+}
+
+func (b *BaseDirs) ResolveConfigWrite(file string) (string, error) {
+	return b.ResolveWrite("config", file)
+}
+func (b *BaseDirs) ResolveDataWrite(file string) (string, error) {
+	return b.ResolveWrite("data", file)
+}
+func (b *BaseDirs) ResolveCacheWrite(file string) (string, error) {
+	return b.ResolveWrite("cache", file)
+}
+func (b *BaseDirs) ResolveStateWrite(file string) (string, error) {
+	return b.ResolveWrite("state", file)
+}
+
+func ResolveWrite(category, file string) (string, error) {
+	return defaults().ResolveWrite(category, file)
+}
+func ResolveConfigWrite(file string) (string, error) { return defaults().ResolveConfigWrite(file) }
+func ResolveDataWrite(file string) (string, error)   { return defaults().ResolveDataWrite(file) }
+func ResolveCacheWrite(file string) (string, error)  { return defaults().ResolveCacheWrite(file) }
+func ResolveStateWrite(file string) (string, error)  { return defaults().ResolveStateWrite(file) }
+
+// probeWrite checks that files can be created in dir, by creating and
+// removing a temporary file.
+func probeWrite(dir string) error {
+	f, err := os.CreateTemp(dir, ".xdg-probe-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// dirPerm returns the permission with which directories of category are
+// created: the runtime directory must only be accessible by the user.
+func dirPerm(category string) os.FileMode {
+	if category == "runtime" {
+		return 0700
+	}
+	return 0755
+}
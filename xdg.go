@@ -8,39 +8,60 @@
 // values, by reading the corresponding environment variables and falling back to
 // specification defaults if necessary.
 //
-//     ConfigHome      // user configuration base directory, e.g. ~/.config
-//     DataHome        // user data files base directory, e.g. ~/.local/share
-//     CacheHome       // user cache files base directory, e.g. ~/.cache
-//     RuntimeDir      // user runtime files base directory, e.g. /run/user/1000
-//     ConfigDirs      // global configuration directories, e.g. /etc/xdg
-//     DataDirs        // global data files directories, e.g. /usr/local/share
-//     AllConfigDirs   // user and global configuration directories
-//     AllDataDirs     // user and global data directories
-//
-// Initialization happens automatically, but can also be explicitely started with
-// the Init function. If no valid path can be constructed, the variable is left
-// blank or empty. If one of the required paths is blank or empty, the program
-// should fail. These variables should be treated as read-only; change them only
-// if you know what you are doing.
+//	ConfigHome      // user configuration base directory, e.g. ~/.config
+//	DataHome        // user data files base directory, e.g. ~/.local/share
+//	CacheHome       // user cache files base directory, e.g. ~/.cache
+//	StateHome       // user state files base directory, e.g. ~/.local/state
+//	BinHome         // user executables directory, e.g. ~/.local/bin
+//	RuntimeDir      // user runtime files base directory, e.g. /run/user/1000
+//	ConfigDirs      // global configuration directories, e.g. /etc/xdg
+//	DataDirs        // global data files directories, e.g. /usr/local/share
+//	AllConfigDirs   // user and global configuration directories
+//	AllDataDirs     // user and global data directories
+//
+// Initialization happens lazily on first use of a package function, but should
+// be explicitely started with the Init function, which returns any errors and
+// makes sure the variables are set. If no valid path can be constructed, the
+// variable is left blank or empty. If one of the required paths is blank or
+// empty, the program should fail. These variables should be treated as
+// read-only; to override them, use the Set* functions, such as SetConfigHome,
+// which validate the new value and keep the package consistent.
+//
+// The package variables and functions are backed by a default BaseDirs.
+// Programs that need to resolve directories for a different environment can
+// create their own with New or NewFromEnviron; the functions below are
+// available on it as methods.
+//
+// Most programs keep their files in a subdirectory named after the program.
+// App returns an AppDirs, which builds such paths consistently and has the
+// same functions as methods.
 //
 // The package has four classes of functions, which should suffice for most needs:
 //
-//     User*           // construct a valid path for user (config|data|...) files
-//     Find*           // find existing (config|data|...) files
-//     Merge*          // execute a function on each found (config|data) file
-//     Open*           // open or create a user (config|data|...) file
+//	User*           // construct a valid path for user (config|data|...) files
+//	Find*           // find existing (config|data|...) files
+//	Merge*          // execute a function on each found (config|data|...) file
+//	Open*           // open or create a user (config|data|...) file
+//
+// Each class also has a generic function (User, Find, FindAll, Merge, MergeR,
+// and Open) that takes the name of a category as first argument: one of the
+// built-in categories "config", "data", "cache", "state", "runtime", and
+// "bin", or a category registered with RegisterCategory. This allows
+// applications to define their own base directories that follow the same
+// rules.
 //
 // Only the Open* functions may alter the filesystem in any way: this is
 // restricted to creating XDG user base directories and files therein. Directories
-// in ConfigDirs and DataDirs are not modified.
+// in ConfigDirs and DataDirs are not modified, unless the user base directory
+// cannot be written to and a Resolve*Write function falls back to them.
 //
 // The XDG Base Directory Specification, henceforth “the specification”, defines
-// several types of files: configuration, data, cache, and runtime files.
+// several types of files: configuration, data, cache, state, and runtime files.
 // The specification can be found at:
 //
-//     http://standards.freedesktop.org/basedir-spec/basedir-spec-latest.html
+//	http://standards.freedesktop.org/basedir-spec/basedir-spec-latest.html
 //
-// Configuration files
+// # Configuration files
 //
 // Configuration files are read from ConfigHome and from ConfigDirs;
 // they are only written in ConfigHome.
@@ -59,7 +80,7 @@
 // ConfigHomeDirs combines ConfigHome and ConfigDirs into one preference
 // ordered set of directories.
 //
-// Data files
+// # Data files
 //
 // Data files are read from DataHome and from DataDirs;
 // they are only written in DataHome.
@@ -74,51 +95,150 @@
 // variable $XDG_DATA_DIRS. If $XDG_CONFIG_DIRS is not set, the default
 // "/usr/local/share:/usr/share" is used.
 //
-// Cache files
+// # Cache files
 //
 // CacheHome is a single base directory relative to which user-specific
 // non-essential (cached) data should be written. This directory is defined by the
 // environment variable $XDG_CACHE_HOME.  If $XDG_CACHE_HOME is not set, the
 // default "$HOME/.cache" is used.
 //
-// Runtime files
+// # State files
+//
+// StateHome is a single base directory relative to which user-specific state
+// data should be written. State data should persist between application
+// restarts, but is not important or portable enough to be stored in DataHome,
+// e.g. logs, history, or the current state of the application. This directory
+// is defined by the environment variable $XDG_STATE_HOME. If $XDG_STATE_HOME
+// is not set, the default "$HOME/.local/state" is used.
+//
+// # Executable files
+//
+// BinHome is a single base directory in which user-specific executables may
+// be stored. The specification does not define an environment variable for
+// it, only the location "$HOME/.local/bin"; this implementation also reads
+// the non-standard $XDG_BIN_HOME, which is used by some tools.
+//
+// # Runtime files
 //
 // RuntimeDir is a single base directory relative to which user-specific
 // runtime files and other file objects should be placed. This directory is
 // defined by the environment variable $XDG_RUNTIME_DIR. If $XDG_RUNTIME_DIR
 // is not set, the following method is used to find an appropriate directory:
 //
-//     path.Join(os.TempDir(), fmt.Sprintf("xdg-%d", os.Getuid()))
+//	filepath.Join(TempDir, fmt.Sprintf("xdg-%d", os.Getuid()))
 //
-// This usually results in paths such as "/tmp/xdg-1000". Normally, we expect
-// something along the lines of "/run/user/1000".
+// where TempDir is $TMPDIR if it is set, or else the temporary directory of
+// the system. This usually results in paths such as "/tmp/xdg-1000". Normally, we expect
+// something along the lines of "/run/user/1000". If DetectRuntimeDir is set,
+// that directory is used if it exists and is suitable. The replacement
+// directory is created with mode 0700 when it is first used,
+// BaseDirs.RuntimeSource records that it is in use, and OnRuntimeFallback
+// can be set to warn the user, as the specification recommends.
 //
 // In this implementation, we assume that the system takes care of removing the
 // XDG runtime directory at shutdown.
+//
+// # User directories
+//
+// The well-known user directories, such as the directory for downloads,
+// are not base directories, but are configured by the xdg-user-dirs tool
+// in $XDG_CONFIG_HOME/user-dirs.dirs. On initialization, UserDirs is set
+// from that file, falling back to /etc/xdg/user-dirs.defaults for the
+// directories that it does not mention. SetUserDir changes one of them,
+// and UpdateUserDirs creates the missing ones in the language of the user,
+// as xdg-user-dirs-update does at login.
+//
+// # Windows
+//
+// On Windows, the XDG variables are honored if they are set, as they are by
+// MSYS and WSL users, but the defaults are the Known Folders: ConfigHome and
+// DataHome are %APPDATA%, StateHome is %LOCALAPPDATA%, CacheHome is
+// %LOCALAPPDATA%\cache, and ConfigDirs and DataDirs are %PROGRAMDATA%. The
+// home directory is $HOME or %USERPROFILE%, lists of directories are
+// separated by ';', and paths are handled with package filepath, so that
+// drive letters and backslashes work.
+//
+// # macOS
+//
+// On macOS, the defaults of the specification are used, which most
+// command-line tools expect. GUI applications can set Native before Init
+// to use ~/Library/Application Support for configuration, data, and state
+// files, ~/Library/Caches for cache files, and the per-user temporary
+// directory as runtime directory. XDG variables that are set are honored in
+// either case.
+//
+// # WebAssembly
+//
+// On js/wasm and wasip1, only the paths are provided: the base directories
+// resolve to their defaults, with a directory in the temporary directory
+// as home if $HOME is not set, so that shared code compiles and runs. The
+// package does not provide a file system there; files are read and written
+// with package os, which works only where the host provides a file system,
+// such as Node.js or the preopened directories of a WASI runtime, and not
+// in a browser.
 package xdg
 
 import (
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
-	"path"
+	"path/filepath"
 	"strings"
+	"sync"
 )
 
 // Getenv reads several environment variables. You can provide your own
 // implementation if you have special needs (e.g. mock testing).
-// If you change Getenv, you need to call Init() again.
+// If you change Getenv after the package has been initialized, you need to
+// call Init() again.
 // The following variables are read:
 //
-//  HOME
-//  XDG_CONFIG_HOME
-//  XDG_DATA_HOME
-//  XDG_CACHE_HOME
-//  XDG_RUNTIME_DIR
-//  XDG_CONFIG_DIRS
-//  XDG_DATA_DIRS
+//	HOME
+//	USERPROFILE, APPDATA, LOCALAPPDATA, PROGRAMDATA (Windows only)
+//	XDG_CONFIG_HOME
+//	XDG_DATA_HOME
+//	XDG_CACHE_HOME
+//	XDG_STATE_HOME
+//	XDG_BIN_HOME
+//	XDG_RUNTIME_DIR
+//	XDG_CONFIG_DIRS
+//	XDG_DATA_DIRS
+//	FLATPAK_ID
+//	TMPDIR
+//	SUDO_USER, SUDO_UID, SUDO_GID
+//	SNAP, SNAP_NAME, SNAP_INSTANCE_NAME, SNAP_USER_DATA, SNAP_USER_COMMON
 var Getenv func(string) string = os.Getenv
 
+// Expand enables a lenient mode, in which a leading ~ and environment
+// variables, such as $HOME, are expanded in the values of XDG variables
+// before they are checked. Some users set XDG_DATA_HOME=~/data literally in
+// their environment, which would otherwise be ignored as a relative path.
+// The specification does not allow this, so Expand is false by default.
+// If you change Expand after the package has been initialized, you need to
+// call Init() again.
+var Expand = false
+
+// FallbackRoot is used in place of the home directory if no valid home
+// directory can be found, neither in $HOME nor in the user database, which
+// is common in containers, where programs often run with a random UID and
+// no environment. For example, if FallbackRoot is
+// "/data", ConfigHome becomes /data/.config instead of being left blank.
+// BaseDirs.HomeFallback reports whether it was used. FallbackRoot must be
+// absolute; by default it is "", which disables the fallback.
+// If you change FallbackRoot after the package has been initialized, you
+// need to call Init() again.
+var FallbackRoot = ""
+
+// Parallelism is the maximum number of base directories that the Find*,
+// FindAll*, and Merge* functions probe concurrently. Probing concurrently
+// reduces latency when there are many global base directories on slow file
+// systems, such as network stores in a long XDG_DATA_DIRS. The results are
+// still in order of preference. If Parallelism is less than 2, which is the
+// default, the base directories are probed one after the other, and Find
+// stops at the first file that exists.
+var Parallelism = 0
+
 var (
 	// Errors contains all errors that occurred during initialization.
 	Errors []error
@@ -128,10 +248,37 @@ var (
 	ErrInvalidHome = errors.New("environment variable HOME is invalid or not set")
 
 	// ErrInvalidPath is returned when attempting to create or open an invalid path.
-	// This means that some XDG variable could not be correctly set.
+	// This means that some XDG variable could not be correctly set, or that the
+	// file is not a relative path inside the base directory, e.g. "../passwd".
 	ErrInvalidPath = errors.New("invalid XDG path used")
+
+	// ErrNotSet is the reason of a VarError if a variable is not set and
+	// there is no default value for it.
+	ErrNotSet = errors.New("variable is not set")
+
+	// ErrNotAbsolute is the reason of a VarError if a variable is set to
+	// a path that is not absolute, which the specification forbids.
+	ErrNotAbsolute = errors.New("path is not absolute")
 )
 
+// VarError records a problem with an XDG environment variable. It is found
+// in the Errors slice for each variable that could not be resolved, so that
+// errors.As can be used to find out which one is affected.
+type VarError struct {
+	Var    string // name of the environment variable, e.g. XDG_CONFIG_HOME
+	Value  string // offending value, or "" if the variable was not set
+	Reason error  // reason why the value is invalid, e.g. ErrNotAbsolute
+}
+
+func (e *VarError) Error() string {
+	if e.Value == "" {
+		return e.Var + ": " + e.Reason.Error()
+	}
+	return fmt.Sprintf("%s: %v: %q", e.Var, e.Reason, e.Value)
+}
+
+func (e *VarError) Unwrap() error { return e.Reason }
+
 var (
 	// ConfigHome is a single base directory relative to which user-specific
 	// configuration files should be written.
@@ -145,6 +292,14 @@ var (
 	// non-essential (cached) data should be written.
 	CacheHome string
 
+	// StateHome is a single base directory relative to which user-specific
+	// state data should be written.
+	StateHome string
+
+	// BinHome is a single base directory in which user-specific executables
+	// may be stored.
+	BinHome string
+
 	// RuntimeDir is a single base directory relative to which user-specific
 	// runtime files and other file objects should be placed.
 	RuntimeDir string
@@ -163,129 +318,179 @@ var (
 	// DataHomeDirs is the same as DataDirs, with DataHome at first place.
 	DataHomeDirs []string
 
-	// home is a single base directory of the user's home directory.
-	// This directory is defined by the environment variable $HOME.
-	//
-	// If $HOME is not set, and is required, then other variables might be empty.
-	home string
+	// std is the default BaseDirs, which the package-level variables mirror
+	// and the package-level functions use.
+	std *BaseDirs
 )
 
-func init() {
-	Init()
-}
-
 // Init initializes this package, reading several environment variables
 // (using Getenv, which you can override if you need to), and setting
-// several package variables.
-//
-// It is normally not necessary to call Init; you only need to do so
-// if you would like to reset the package (e.g. because you changed
-// Getenv).
-func Init() {
-	Errors = []error{}
-	home = Getenv("HOME")
-	if !path.IsAbs(home) {
-		home = ""
-		Errors = append(Errors, ErrInvalidHome)
-	}
+// several package variables. All errors that occurred are combined into
+// the returned error; nil is returned if every variable could be resolved.
+//
+// Initialization happens lazily on first use of a package function, but
+// programs should call Init explicitly at startup, so that they can check
+// the error and so that the package variables are set before being read.
+// Calling Init again resets the package (e.g. because you changed Getenv).
+func Init() error {
+	return Reload()
+}
 
-	ConfigHome = xdgPath("XDG_CONFIG_HOME", "$HOME/.config")
-	DataHome = xdgPath("XDG_DATA_HOME", "$HOME/.local/share")
-	CacheHome = xdgPath("XDG_CACHE_HOME", "$HOME/.cache")
-	tmp := path.Join(os.TempDir(), fmt.Sprintf("xdg-%d", os.Getuid()))
-	RuntimeDir = xdgPath("XDG_RUNTIME_DIR", tmp)
-	ConfigDirs = xdgPaths("XDG_CONFIG_DIRS", "/etc/xdg")
-	DataDirs = xdgPaths("XDG_DATA_DIRS", "/usr/local/share:/usr/share")
-	ConfigHomeDirs = combine(ConfigHome, ConfigDirs)
-	DataHomeDirs = combine(DataHome, DataDirs)
+// Reload re-reads the environment and re-evaluates all XDG variables,
+// returning any resolution errors in the same way as Init.
+//
+// The default BaseDirs is replaced atomically, so it is safe to call Reload
+// while other goroutines use the package functions. The package variables
+// are also updated, but reading them concurrently with Reload is not safe.
+// Reload also invalidates the cache that CacheLookups enables.
+func Reload() error {
+	once.Do(func() {})
+	InvalidateCache()
+	return load().Err()
 }
 
-func xdgPath(env, def string) string {
-	x := Getenv(env)
+// Err returns all errors that occurred during initialization of the package,
+// joined into one error, or nil if there were none. See BaseDirs.Err.
+func Err() error { return defaults().Err() }
 
-	if x == "" {
-		if strings.Contains(def, "$HOME") {
-			if home != "" {
-				x = strings.Replace(def, "$HOME", home, -1)
-			}
-		} else {
-			x = def
-		}
-	}
+// ErrFor returns the errors that occurred during initialization of the
+// environment variable env, joined into one error, or nil if there were
+// none. See BaseDirs.ErrFor.
+func ErrFor(env string) error { return defaults().ErrFor(env) }
 
-	// The XDG specification states:
-	//
-	//  All paths set in these environment variables must be absolute. If an
-	//  implementation encounters a relative path in any of these variables it
-	//  should consider the path invalid and ignore it.
-	if path.IsAbs(x) {
-		return x
-	}
-	Errors = append(Errors, errors.New("no value set for "+env))
-	return ""
-}
+// Default returns the default BaseDirs, which backs the package variables
+// and functions, initializing the package if necessary. Reload and the Set*
+// functions replace it rather than change it, so a BaseDirs that Default
+// returned keeps its directories, and Default should be called again to
+// see their changes.
+func Default() *BaseDirs { return defaults() }
 
-func xdgPaths(env, def string) []string {
-	xs := Getenv(env)
+var (
+	// once guards the lazy initialization of the package.
+	once sync.Once
 
-	if xs == "" {
-		xs = def
-	}
+	// mu guards std and the package variables.
+	mu sync.RWMutex
+)
 
-	var fs []string
-	for _, x := range strings.Split(xs, string(os.PathListSeparator)) {
-		// See comment in xdgPath.
-		if path.IsAbs(x) {
-			fs = append(fs, x)
-		} else {
-			Errors = append(Errors, errors.New("ignoring "+env+" path element: "+x))
-		}
-	}
-	return fs
+// defaults returns std, initializing the package if that has not happened yet.
+func defaults() *BaseDirs {
+	once.Do(func() { load() })
+	mu.RLock()
+	defer mu.RUnlock()
+	return std
 }
 
-// combine x and xs to a single slice, where x is in the front.
-// If x is empty, xs is returned.
-func combine(x string, xs []string) []string {
-	if x == "" {
-		return xs
-	}
+// load resolves a new BaseDirs and sets std and the package variables from it.
+func load() *BaseDirs {
+	b := New()
+	u, _ := b.UserDirs()
+	mu.Lock()
+	defer mu.Unlock()
+	setDefault(b)
+	UserDirs = u
+	return b
+}
 
-	n := len(xs) + 1
-	ns := make([]string, n)
+// setDefault sets std and the package variables from b, except for UserDirs,
+// which needs to read files. mu must be held.
+func setDefault(b *BaseDirs) {
+	std = b
+	Errors = b.Errors
+	ConfigHome = b.ConfigHome
+	DataHome = b.DataHome
+	CacheHome = b.CacheHome
+	StateHome = b.StateHome
+	BinHome = b.BinHome
+	RuntimeDir = b.RuntimeDir
+	ConfigDirs = b.ConfigDirs
+	DataDirs = b.DataDirs
+	ConfigHomeDirs = b.ConfigPaths()
+	DataHomeDirs = b.DataPaths()
+}
 
-	ns[0] = x
-	for i := 1; i < n; i++ {
-		ns[i] = xs[i-1]
-	}
-	return ns
+func ConfigPaths() []string  { return defaults().ConfigPaths() }
+func DataPaths() []string    { return defaults().DataPaths() }
+func CachePaths() []string   { return defaults().CachePaths() }
+func StatePaths() []string   { return defaults().StatePaths() }
+func RuntimePaths() []string { return defaults().RuntimePaths() }
+
+func Paths(category string) []string                  { return defaults().Paths(category) }
+func User(category, file string) string               { return defaults().User(category, file) }
+func Find(category, file string) string               { return defaults().Find(category, file) }
+func FindAll(category, file string) []string          { return defaults().FindAll(category, file) }
+func Merge(category, file string, f MergeFunc) error  { return defaults().Merge(category, file, f) }
+func MergeR(category, file string, f MergeFunc) error { return defaults().MergeR(category, file, f) }
+func Open(category, file string, flag int) (*os.File, error) {
+	return defaults().Open(category, file, flag)
 }
 
-func UserConfig(file string) string  { return join(ConfigHome, file) }
-func UserData(file string) string    { return join(DataHome, file) }
-func UserCache(file string) string   { return join(CacheHome, file) }
-func UserRuntime(file string) string { return join(RuntimeDir, file) }
+func UserConfig(file string) string  { return defaults().UserConfig(file) }
+func UserData(file string) string    { return defaults().UserData(file) }
+func UserCache(file string) string   { return defaults().UserCache(file) }
+func UserState(file string) string   { return defaults().UserState(file) }
+func UserRuntime(file string) string { return defaults().UserRuntime(file) }
 
+// join returns the path of file relative to dir, or "" if dir is empty or
+// file is not a valid relative path.
 func join(dir, file string) string {
-	if dir == "" {
+	if dir == "" || !validFile(file) {
 		return ""
 	}
-	p := path.Join(dir, file)
-	if !path.IsAbs(p) {
-		return ""
+	// dir is absolute, unless Strictness is Permissive.
+	return filepath.Join(dir, file)
+}
+
+// validFile returns true if file is a relative path that does not contain
+// any ".." elements, so that joining it to a base directory cannot result
+// in a path outside of that directory. This protects programs that take
+// file names from untrusted input, such as "../../etc/shadow". On Windows,
+// backslashes separate elements as well, and paths with a drive letter or
+// a leading separator are not relative.
+func validFile(file string) bool {
+	if filepath.IsAbs(file) || filepath.VolumeName(file) != "" {
+		return false
+	}
+	if file != "" && os.IsPathSeparator(file[0]) {
+		return false
 	}
-	return p
+	sep := func(r rune) bool { return r == '/' || r < 0x80 && os.IsPathSeparator(uint8(r)) }
+	for _, s := range strings.FieldsFunc(file, sep) {
+		if s == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// errInvalidFile returns an error wrapping ErrInvalidPath, which states that
+// the operation op failed because file is not a valid relative path.
+func errInvalidFile(op, file string) error {
+	return &os.PathError{Op: op, Path: file, Err: ErrInvalidPath}
 }
 
-func FindConfig(file string) string      { return find(file, ConfigHomeDirs) }
-func FindData(file string) string        { return find(file, DataHomeDirs) }
-func FindCache(file string) string       { return find(file, []string{CacheHome}) }
-func FindRuntime(file string) string     { return find(file, []string{RuntimeDir}) }
-func FindAllConfig(file string) []string { return findAll(file, ConfigHomeDirs) }
-func FindAllData(file string) []string   { return findAll(file, DataHomeDirs) }
+// The Find* functions search for file relative to the user base directory
+// and then to each of the global base directories, in order of preference.
+// Entries that cannot be accessed, for example because they do not exist or
+// permission is denied, are skipped. Find* returns the absolute path of the
+// first match, or "" if there is none; FindAll* returns all matches.
+
+func FindConfig(file string) string      { return defaults().FindConfig(file) }
+func FindData(file string) string        { return defaults().FindData(file) }
+func FindCache(file string) string       { return defaults().FindCache(file) }
+func FindState(file string) string       { return defaults().FindState(file) }
+func FindRuntime(file string) string     { return defaults().FindRuntime(file) }
+func FindAllConfig(file string) []string { return defaults().FindAllConfig(file) }
+func FindAllData(file string) []string   { return defaults().FindAllData(file) }
 
 // find returns the first file that exists, else "".
 func find(file string, paths []string) string {
+	if Parallelism > 1 || CacheLookups {
+		if hs := findHits(file, paths); len(hs) > 0 {
+			return hs[0].Path
+		}
+		return ""
+	}
 	for _, dir := range paths {
 		p := join(dir, file)
 		if _, err := os.Stat(p); err != nil {
@@ -296,14 +501,12 @@ func find(file string, paths []string) string {
 	return ""
 }
 
+// findAll returns all files that exist, in the order of paths.
 func findAll(file string, paths []string) []string {
-	ps := make([]string, 0, len(paths))
-	for _, dir := range paths {
-		p := join(dir, file)
-		if _, err := os.Stat(p); err != nil {
-			continue
-		}
-		ps = append(ps, p)
+	hs := findHits(file, paths)
+	ps := make([]string, len(hs))
+	for i, h := range hs {
+		ps[i] = h.Path
 	}
 	return ps
 }
@@ -317,120 +520,145 @@ func findAll(file string, paths []string) []string {
 type MergeFunc func(filepath string) error
 
 // Skip can be returned by a MergeFunc which causes the Merge* functions
-// to skip the rest of the files to be merged.
+// to skip the rest of the files to be merged. It is compared with errors.Is,
+// so it may also be wrapped, e.g. with fmt.Errorf("...: %w", xdg.Skip).
 var Skip = errors.New("skip the rest of the files to be merged")
 
-func MergeConfig(file string, f MergeFunc) error  { return merge(file, f, ConfigHomeDirs) }
-func MergeConfigR(file string, f MergeFunc) error { return mergeR(file, f, ConfigHomeDirs) }
-func MergeData(file string, f MergeFunc) error    { return merge(file, f, DataHomeDirs) }
-func MergeDataR(file string, f MergeFunc) error   { return mergeR(file, f, DataHomeDirs) }
+// SkipDir can be returned by the fs.WalkDirFunc given to the Walk*
+// functions to skip the directory that is passed to it, or the rest of the
+// directory that contains the file. It is the same as fs.SkipDir, but
+// unlike with fs.WalkDir, it may also be wrapped.
+var SkipDir = fs.SkipDir
+
+func MergeConfig(file string, f MergeFunc) error  { return defaults().MergeConfig(file, f) }
+func MergeConfigR(file string, f MergeFunc) error { return defaults().MergeConfigR(file, f) }
+func MergeData(file string, f MergeFunc) error    { return defaults().MergeData(file, f) }
+func MergeDataR(file string, f MergeFunc) error   { return defaults().MergeDataR(file, f) }
+func MergeState(file string, f MergeFunc) error   { return defaults().MergeState(file, f) }
+func MergeCache(file string, f MergeFunc) error   { return defaults().MergeCache(file, f) }
+func MergeRuntime(file string, f MergeFunc) error { return defaults().MergeRuntime(file, f) }
 
 func mergeR(file string, f MergeFunc, paths []string) error {
-	var err error
-	for s := range reverse(findAll(file, paths)) {
-		if err = f(s); err != nil {
-			break
-		}
-	}
-	if err == Skip {
-		return nil
-	}
-	return err
+	return mergeFiles(reverse(findAll(file, paths)), f)
 }
 
 func merge(file string, f MergeFunc, paths []string) error {
+	return mergeFiles(findAll(file, paths), f)
+}
+
+// mergeFiles calls f on each of files in order, until f returns an error.
+func mergeFiles(files []string, f MergeFunc) error {
 	var err error
-	for _, s := range findAll(file, paths) {
+	for _, s := range files {
 		if err = f(s); err != nil {
 			break
 		}
 	}
-	if err == Skip {
+	if errors.Is(err, Skip) {
 		return nil
 	}
 	return err
 }
 
-func reverse(xs []string) <-chan string {
-	ch := make(chan string)
-	go func() {
-		for i := len(xs); i != 0; i-- {
-			ch <- xs[i-1]
-		}
-		close(ch)
-	}()
-	return ch
+// reverse returns a reversed copy of xs.
+func reverse(xs []string) []string {
+	rs := make([]string, len(xs))
+	for i, x := range xs {
+		rs[len(xs)-1-i] = x
+	}
+	return rs
 }
 
-func OpenConfig(file string, flag int) (*os.File, error) { return open(UserConfig(file), flag) }
-func OpenData(file string, flag int) (*os.File, error)   { return open(UserData(file), flag) }
-func OpenCache(file string, flag int) (*os.File, error)  { return open(UserCache(file), flag) }
-func OpenRuntime(file string, flag int) (*os.File, error) {
-	// TODO: Make sure that the runtime directory is only readable by the user.
-	_, err := os.Stat(RuntimeDir)
-	if err != nil {
-		if os.IsNotExist(err) {
-			err = os.MkdirAll(RuntimeDir, os.ModeDir|0700)
-			if err != nil {
-				return nil, err
-			}
-			_, err = os.Stat(RuntimeDir)
-			if err != nil {
-				// This really should never happen, but you never know!
-				return nil, err
-			}
-		} else {
-			return nil, err
-		}
-	}
+func OpenConfig(file string, flag int) (*os.File, error)  { return defaults().OpenConfig(file, flag) }
+func OpenData(file string, flag int) (*os.File, error)    { return defaults().OpenData(file, flag) }
+func OpenCache(file string, flag int) (*os.File, error)   { return defaults().OpenCache(file, flag) }
+func OpenState(file string, flag int) (*os.File, error)   { return defaults().OpenState(file, flag) }
+func OpenRuntime(file string, flag int) (*os.File, error) { return defaults().OpenRuntime(file, flag) }
 
-	err = os.Chown(RuntimeDir, os.Getuid(), os.Getgid())
-	if err != nil {
-		return nil, err
-	}
+// The Open*File functions are like the Open* functions, but create the file
+// with the permission perm (before umask), like os.OpenFile. The Open*
+// functions use 0644, except for runtime files, which use 0600.
 
-	return open(UserRuntime(file), flag)
+func OpenFile(category, file string, flag int, perm os.FileMode) (*os.File, error) {
+	return defaults().OpenFile(category, file, flag, perm)
+}
+func OpenConfigFile(file string, flag int, perm os.FileMode) (*os.File, error) {
+	return defaults().OpenConfigFile(file, flag, perm)
+}
+func OpenDataFile(file string, flag int, perm os.FileMode) (*os.File, error) {
+	return defaults().OpenDataFile(file, flag, perm)
+}
+func OpenCacheFile(file string, flag int, perm os.FileMode) (*os.File, error) {
+	return defaults().OpenCacheFile(file, flag, perm)
+}
+func OpenStateFile(file string, flag int, perm os.FileMode) (*os.File, error) {
+	return defaults().OpenStateFile(file, flag, perm)
+}
+func OpenRuntimeFile(file string, flag int, perm os.FileMode) (*os.File, error) {
+	return defaults().OpenRuntimeFile(file, flag, perm)
 }
 
-// open opens the given file with the appropriate flag and permission.
-// The flag should be specified, depending on purpose. If O_CREATE is
-// given, directories leading to the flag are also created.
-//
-//  O_RDONLY    open the file read-only.
-//  O_WRONLY    open the file write-only.
-//  O_RDWR      open the file read-write.
-//  O_APPEND    append data to the file when writing.
-//  O_CREATE    create a new file if none exists.
-//  O_EXCL      used with O_CREATE, file must not exist
-//  O_SYNC      open for synchronous I/O.
-//  O_TRUNC     if possible, truncate file when opened.
-func open(file string, flag int) (*os.File, error) {
-	if file == "" {
-		return nil, ErrInvalidPath
+// open opens file relative to the base directory dir, which is defined by
+// the environment variable env, with the appropriate flag and permission.
+// The flag should be specified, depending on purpose. If O_CREATE is given,
+// directories leading to the file are also created with the permission
+// dperm, and the file itself is created with the permission perm.
+//
+//	O_RDONLY    open the file read-only.
+//	O_WRONLY    open the file write-only.
+//	O_RDWR      open the file read-write.
+//	O_APPEND    append data to the file when writing.
+//	O_CREATE    create a new file if none exists.
+//	O_EXCL      used with O_CREATE, file must not exist
+//	O_SYNC      open for synchronous I/O.
+//	O_TRUNC     if possible, truncate file when opened.
+//
+// If dir is empty, because env could not be resolved, or file is not a valid
+// relative path, an error wrapping ErrInvalidPath is returned.
+func (b *BaseDirs) open(dir, env, file string, flag int, dperm, perm os.FileMode) (*os.File, error) {
+	if dir == "" {
+		return nil, errUnresolved(env)
+	}
+	p := join(dir, file)
+	if p == "" {
+		return nil, errInvalidFile("open", file)
 	}
 
 	if flag&os.O_CREATE != 0 {
 		// Check if we need to try to create a directory.
-		err := MkdirAll(path.Dir(file))
+		err := b.mkdirAll(filepath.Dir(p), dperm)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	return os.OpenFile(file, flag, 0700)
+	f, err := os.OpenFile(p, flag, perm)
+	if err == nil && flag&os.O_CREATE != 0 {
+		if err = b.chown(p); err != nil {
+			f.Close()
+			return nil, err
+		}
+		InvalidateCache()
+	}
+	return f, err
+}
+
+// errUnresolved returns a VarError wrapping ErrInvalidPath, which states that
+// the environment variable env could not be resolved.
+func errUnresolved(env string) error {
+	return &VarError{Var: env, Reason: ErrInvalidPath}
 }
 
 // MkdirAll creates dirpath if it does not already exist.
 //
 // Example:
 //
-//  xdg.MkdirAll(xdg.UserData("dromi"))
-//  db, err := OpenDatabase(xdg.UserData("dromi/datbase.db"))
-//
+//	xdg.MkdirAll(xdg.UserData("dromi"))
+//	db, err := OpenDatabase(xdg.UserData("dromi/datbase.db"))
 func MkdirAll(dirpath string) error {
 	// TODO: am I swallowing err?
 	if _, err := os.Stat(dirpath); os.IsNotExist(err) {
-		return os.MkdirAll(dirpath, os.ModeDir|0700)
+		return defaults().mkdirAll(dirpath, os.ModeDir|0700)
 	}
 	return nil
 }
@@ -0,0 +1,48 @@
+// Copyright (c) 2015, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestValidFile(t *testing.T) {
+	windows := runtime.GOOS == "windows"
+	tests := []struct {
+		file    string
+		unix    bool // whether file is valid on other systems
+		windows bool // whether file is valid on Windows
+	}{
+		{`dromi/config.toml`, true, true},
+		{`dromi\config.toml`, true, true},
+		{`dromi/sub\config.toml`, true, true},
+		{`..config`, true, true},
+		{`dromi/..config`, true, true},
+		{``, true, true},
+		{`..`, false, false},
+		{`../etc/shadow`, false, false},
+		{`dromi/../../etc/shadow`, false, false},
+		{`/etc/shadow`, false, false},
+		{`..\etc\shadow`, true, false},
+		{`dromi\..\..\etc\shadow`, true, false},
+		{`dromi/..\..\etc`, true, false},
+		{`\etc\shadow`, true, false},
+		{`C:\Windows\win.ini`, true, false},
+		{`C:/Windows/win.ini`, true, false},
+		{`C:win.ini`, true, false},
+		{`\\server\share\file`, true, false},
+		{`//server/share/file`, false, false},
+	}
+	for _, tt := range tests {
+		want := tt.unix
+		if windows {
+			want = tt.windows
+		}
+		if got := validFile(tt.file); got != want {
+			t.Errorf("validFile(%q) = %v; want %v", tt.file, got, want)
+		}
+	}
+}